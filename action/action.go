@@ -1,6 +1,8 @@
 package action
 
 import (
+	"path/filepath"
+
 	cli "github.com/urfave/cli/v2"
 	"github.com/zeromicro/go-zero/tools/goctl/plugin"
 
@@ -9,21 +11,55 @@ import (
 
 // Generator generates the swagger json doc.
 func Generator(ctx *cli.Context) error {
+	// the default file name is set by the generator, since it's the base name of the documents with per.
 	fileName := ctx.String("filename")
 
-	if len(fileName) == 0 {
-		fileName = "rest.swagger.json"
-	}
-
 	p, err := plugin.NewPlugin()
 	if err != nil {
 		return err
 	}
-	basepath := ctx.String("basepath")
-	host := ctx.String("host")
-	schemes := ctx.String("schemes")
-	pack := ctx.String("pack")
-	response := ctx.String("response")
 
-	return generate.Do(fileName, host, basepath, schemes, pack, response, p)
+	c := &generate.Config{}
+	if configFile := ctx.String("config"); len(configFile) > 0 {
+		// relative config file is located from the api file directory
+		if !filepath.IsAbs(configFile) {
+			configFile = filepath.Join(filepath.Dir(p.ApiFilePath), configFile)
+		}
+		c, err = generate.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// command options take precedence over the config file
+	overwrite(&c.BasePath, ctx.String("basepath"))
+	overwrite(&c.Host, ctx.String("host"))
+	overwrite(&c.Schemes, ctx.String("schemes"))
+	overwrite(&c.Pack, ctx.String("pack"))
+	overwrite(&c.Response, ctx.String("response"))
+	overwrite(&c.Server, ctx.String("server"))
+	overwrite(&c.Include, ctx.String("include"))
+	overwrite(&c.Exclude, ctx.String("exclude"))
+	overwrite(&c.Split, ctx.String("split"))
+	overwrite(&c.Per, ctx.String("per"))
+	overwrite(&c.Sort, ctx.String("sort"))
+
+	return generate.Do(fileName, c, p)
+}
+
+// Bundler bundles the split swagger documents into one file.
+func Bundler(ctx *cli.Context) error {
+	output := ctx.String("output")
+
+	if len(output) == 0 {
+		output = "bundled.swagger.json"
+	}
+
+	return generate.Bundle(ctx.String("input"), output)
+}
+
+func overwrite(dst *string, value string) {
+	if len(value) > 0 {
+		*dst = value
+	}
 }
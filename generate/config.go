@@ -0,0 +1,122 @@
+package generate
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Config represents the swagger generation config.
+type Config struct {
+	Host     string `json:"host"`     // api request address
+	BasePath string `json:"basePath"` // url request prefix
+	Schemes  string `json:"schemes"`  // swagger support schemes: http, https, ws, wss
+	Pack     string `json:"pack"`     // default outer packaging response name
+	Response string `json:"response"` // default outer packaging response structure
+	Consumes string `json:"consumes"` // default request media types of the routes with body, separated by commas
+	Server   string `json:"server"`   // the name of the server to render host, basePath and schemes, the first one by default
+
+	// Servers declares the servers of the environments, which are output as x-servers,
+	// e.g. [{"name": "prod", "url": "https://{region}.example.com/v1", "description": "生产环境",
+	// "variables": {"region": {"default": "cn", "enum": ["cn", "us"]}}}].
+	// the selected one renders host, basePath and schemes unless they are specified.
+	Servers json.RawMessage `json:"servers"`
+
+	// FormIn is the default location of the form members on the routes with body: query or body,
+	// empty means they are in the body only if there are no json members, same as before.
+	FormIn string `json:"formIn"`
+
+	// PlainFileArrayName names the file array parameters without the "[]" suffix, e.g. "photos" instead of "photos[]",
+	// which changes the multipart field names of the uploads, so it's disabled by default.
+	PlainFileArrayName bool `json:"plainFileArrayName"`
+
+	// Info overrides the info() block of the api file with its non-empty fields, which is the swagger info object,
+	// e.g. {"contact": {"name": "support", "email": "support@example.com"}, "license": {"name": "MIT"}}.
+	Info json.RawMessage `json:"info"`
+	// ExternalDocs overrides the docs_url and docs_desc of the info() block,
+	// e.g. {"description": "more documents", "url": "https://example.com/docs"}.
+	ExternalDocs json.RawMessage `json:"externalDocs"`
+
+	// Tags declares the tags with description and external docs in display order,
+	// e.g. [{"name": "order", "description": "订单管理", "externalDocs": {"url": "https://example.com"}}].
+	Tags json.RawMessage `json:"tags"`
+	// TagNaming is the naming of the group tags: nested or plain, the default one is nested,
+	// nested tags are joined under the service name and the group like "service/group",
+	// plain tags are the swtags or the group as they are.
+	TagNaming string `json:"tagNaming"`
+	// TagGroups declares the x-tagGroups which groups the tags into sections,
+	// e.g. [{"name": "交易", "tags": ["order", "refund"]}].
+	TagGroups json.RawMessage `json:"tagGroups"`
+
+	// OperationID is the operationId strategy: handler, group_handler, method_path,
+	// or a custom template with {group}, {handler}, {method} and {path}, the default one is handler,
+	// the other values without any placeholder are rejected.
+	OperationID string `json:"operationId"`
+
+	// CollectionFormat is the default collection format of the array parameters: csv, ssv, tsv, pipes or multi,
+	// the default one is multi, which is same as how go-zero binds the repeated keys.
+	CollectionFormat string `json:"collectionFormat"`
+
+	// SynthesizeExamples synthesizes the examples of the definition properties and the parameters which have no examples,
+	// by the enums, ranges, lengths, formats and field names, ExampleSeed makes the random values stable across runs.
+	SynthesizeExamples bool  `json:"synthesizeExamples"`
+	ExampleSeed        int64 `json:"exampleSeed"`
+
+	// Include and Exclude filter the routes by the selectors separated by commas,
+	// a route is rendered if it matches any of the include selectors and none of the exclude selectors,
+	// the selectors are group:<pattern>, prefix:<path>, tag:<pattern>, handler:<pattern> and internal,
+	// e.g. "group:admin*,internal", and the definitions are pruned accordingly.
+	Include string `json:"include"`
+	Exclude string `json:"exclude"`
+
+	// Split splits the output into the root document and the files of the paths by tag or group,
+	// the definitions are in definitions.json, or in definitions/{type}.json for each type if SplitDefinitions is type.
+	Split            string `json:"split"`
+	SplitDefinitions string `json:"splitDefinitions"`
+
+	// Per emits one document per group, prefix or version segment of the path like v1,
+	// FilenameTemplate is the file name of the documents with {service} and {name}, which is the group,
+	// the prefix or the version, the default one is {service}-{name}.swagger.json, the -filename option overrides it
+	// as the base name of the documents, e.g. api.swagger.json for api-{name}.swagger.json,
+	// Versions overrides the info version of the documents by name, the version is the default one of per version.
+	Per              string            `json:"per"`
+	FilenameTemplate string            `json:"filenameTemplate"`
+	Versions         map[string]string `json:"versions"`
+
+	// PruneDefinitions emits only the definitions which are transitively referenced by the operations
+	// and the pack wrappers, and reports the api types which are not used by any route.
+	PruneDefinitions bool `json:"pruneDefinitions"`
+
+	// Packs declares the named outer packaging responses, the key is the response name,
+	// the value is the response structure, which has the same format as Response.
+	// groups can select one of them by @server(pack: xxx),
+	// and routes can override it by @doc(pack: "xxx"), "none" means no packaging.
+	Packs map[string]json.RawMessage `json:"packs"`
+
+	// Parameters declares the shared parameters, the key is the parameter definition name,
+	// the value is the swagger parameter object, e.g. {"name": "X-Request-Id", "in": "header", "type": "string"}.
+	// the header and query parameters of the operations with the same name and location refer to them.
+	Parameters map[string]json.RawMessage `json:"parameters"`
+	// ShareEmbedParameters makes the header and query parameters of the embedded structs
+	// which are embedded by multiple request types become the shared parameters.
+	ShareEmbedParameters bool `json:"shareEmbedParameters"`
+
+	// Sort is the order of the tags, the operation parameters and the schema properties,
+	// declaration keeps the order in the api file and the config, alphabetical sorts them by name,
+	// the default one is declaration, the output is reproducible in both modes.
+	Sort string `json:"sort"`
+}
+
+// LoadConfig loads the swagger generation config from the json file.
+func LoadConfig(filename string) (*Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"reflect"
+	"sort"
 )
 
 var swaggerMapTypes = map[string]reflect.Kind{
@@ -86,10 +87,45 @@ type swaggerObject struct {
 	Produces            []string                            `json:"produces"`
 	Paths               swaggerPathsObject                  `json:"paths"`
 	Definitions         swaggerDefinitionsObject            `json:"definitions"`
+	Parameters          swaggerParameterDefinitionsObject   `json:"parameters,omitempty"`
 	StreamDefinitions   swaggerDefinitionsObject            `json:"x-stream-definitions,omitempty"`
 	SecurityDefinitions swaggerSecurityDefinitionsObject    `json:"securityDefinitions,omitempty"`
 	Security            []swaggerSecurityRequirementObject  `json:"security,omitempty"`
+	Tags                []swaggerTagObject                  `json:"tags,omitempty"`
+	TagGroups           []swaggerTagGroupObject             `json:"x-tagGroups,omitempty"`
 	ExternalDocs        *swaggerExternalDocumentationObject `json:"externalDocs,omitempty"`
+	Servers             []swaggerServerObject               `json:"x-servers,omitempty"`
+
+	// unusedTypes are the api types which are not used by any route, they are not marshaled.
+	unusedTypes []string
+}
+
+// https://spec.openapis.org/oas/v3.0.3#server-object
+// swagger 2.0 does not support the servers, so they are output as x-servers.
+type swaggerServerObject struct {
+	URL         string                                 `json:"url"`
+	Description string                                 `json:"description,omitempty"`
+	Variables   map[string]swaggerServerVariableObject `json:"variables,omitempty"`
+}
+
+// https://spec.openapis.org/oas/v3.0.3#server-variable-object
+type swaggerServerVariableObject struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// http://swagger.io/specification/#tagObject
+type swaggerTagObject struct {
+	Name         string                              `json:"name"`
+	Description  string                              `json:"description,omitempty"`
+	ExternalDocs *swaggerExternalDocumentationObject `json:"externalDocs,omitempty"`
+}
+
+// https://redocly.com/docs/api-reference-docs/specification-extensions/x-tag-groups
+type swaggerTagGroupObject struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
 }
 
 // http://swagger.io/specification/#securityDefinitionsObject
@@ -118,11 +154,19 @@ type swaggerPathsObject map[string]swaggerPathItemObject
 
 // http://swagger.io/specification/#pathItemObject
 type swaggerPathItemObject struct {
+	Ref    string                  `json:"$ref,omitempty"`
 	Get    *swaggerOperationObject `json:"get,omitempty"`
 	Delete *swaggerOperationObject `json:"delete,omitempty"`
 	Post   *swaggerOperationObject `json:"post,omitempty"`
 	Put    *swaggerOperationObject `json:"put,omitempty"`
 	Patch  *swaggerOperationObject `json:"patch,omitempty"`
+
+	Extensions swaggerExtensions `json:"-"`
+}
+
+func (o swaggerPathItemObject) MarshalJSON() ([]byte, error) {
+	type alias swaggerPathItemObject
+	return marshalWithExtensions(alias(o), o.Extensions)
 }
 
 // http://swagger.io/specification/#operationObject
@@ -137,10 +181,24 @@ type swaggerOperationObject struct {
 	} `json:"requestBody,omitempty"`
 	Tags       []string `json:"tags,omitempty"`
 	Deprecated bool     `json:"deprecated,omitempty"`
+	Sunset     string   `json:"x-sunset,omitempty"`
 
 	Consumes     []string                            `json:"consumes,omitempty"`
+	Produces     []string                            `json:"produces,omitempty"`
 	Security     *[]swaggerSecurityRequirementObject `json:"security,omitempty"`
 	ExternalDocs *swaggerExternalDocumentationObject `json:"externalDocs,omitempty"`
+
+	Extensions swaggerExtensions `json:"-"`
+
+	// exampleSources is the example sources of the @doc, which are rendered after the definitions
+	exampleSources map[string]string
+	// group is the group of the @server, which is used to split the output
+	group string
+}
+
+func (o swaggerOperationObject) MarshalJSON() ([]byte, error) {
+	type alias swaggerOperationObject
+	return marshalWithExtensions(alias(o), o.Extensions)
 }
 
 type (
@@ -148,8 +206,13 @@ type (
 	swaggerContentObject    map[string]swaggerParametersObject
 )
 
+// http://swagger.io/specification/#parametersDefinitionsObject
+type swaggerParameterDefinitionsObject map[string]swaggerParameterObject
+
 // http://swagger.io/specification/#parameterObject
 type swaggerParameterObject struct {
+	// Ref refers to the parameter definition, if this is defined all other fields are ignored
+	Ref              string              `json:"$ref,omitempty"`
 	Name             string              `json:"name"`
 	Description      string              `json:"description,omitempty"`
 	In               string              `json:"in,omitempty"`
@@ -169,13 +232,45 @@ type swaggerParameterObject struct {
 	MaxItems         uint64              `json:"maxItems,omitempty"`
 	MaxLength        uint64              `json:"maxLength,omitempty"`
 	ExclusiveMaximum bool                `json:"exclusiveMaximum,omitempty"`
+	Deprecated       bool                `json:"x-deprecated,omitempty"`
 
 	// Or you can explicitly refer to another type. If this is defined all
 	// other fields should be empty
 	Schema *swaggerSchemaObject `json:"schema,omitempty"`
+
+	*swaggerFileExtensions
+
+	Examples   swaggerExamplesObject `json:"x-examples,omitempty"`
+	Extensions swaggerExtensions     `json:"-"`
+
+	// origin is the name of the embedded struct which the parameter comes from
+	origin string
+}
+
+func (o swaggerParameterObject) MarshalJSON() ([]byte, error) {
+	if o.Ref != "" {
+		return json.Marshal(struct {
+			Ref string `json:"$ref"`
+		}{Ref: o.Ref})
+	}
+
+	type alias swaggerParameterObject
+	return marshalWithExtensions(alias(o), o.Extensions)
+}
+
+// swaggerFileExtensions describes the file parameter which swagger 2.0 can not express.
+type swaggerFileExtensions struct {
+	Multiple  bool     `json:"x-multiple,omitempty"`
+	MimeTypes []string `json:"x-mimeTypes,omitempty"`
+	MaxSize   int64    `json:"x-maxSize,omitempty"`
+	MinCount  uint64   `json:"x-minCount,omitempty"`
+	MaxCount  uint64   `json:"x-maxCount,omitempty"`
 }
 
 func (o *swaggerParameterObject) Copy(s *swaggerSchemaObject) {
+	if s.Format != "" {
+		o.Format = s.Format
+	}
 	o.Enum = s.Enum
 	o.Minimum = s.Minimum
 	o.MinItems = s.MinItems
@@ -183,7 +278,7 @@ func (o *swaggerParameterObject) Copy(s *swaggerSchemaObject) {
 	o.ExclusiveMinimum = s.ExclusiveMinimum
 	o.Maximum = s.Maximum
 	o.MaxItems = s.MaxItems
-	o.MinLength = s.MaxLength
+	o.MaxLength = s.MaxLength
 	o.ExclusiveMaximum = s.ExclusiveMaximum
 }
 
@@ -203,15 +298,22 @@ type schemaCore struct {
 	Default string   `json:"default,omitempty"`
 }
 
-type swaggerItemsObject schemaCore
+// swaggerItemsObject is the full schema of the array items, so the inline object items keep their properties.
+type swaggerItemsObject swaggerSchemaObject
+
+func (o swaggerItemsObject) MarshalJSON() ([]byte, error) {
+	return swaggerSchemaObject(o).MarshalJSON()
+}
 
 // http://swagger.io/specification/#responsesObject
 type swaggerResponsesObject map[string]swaggerResponseObject
 
 // http://swagger.io/specification/#responseObject
 type swaggerResponseObject struct {
-	Description string              `json:"description"`
-	Schema      swaggerSchemaObject `json:"schema"`
+	Description   string                     `json:"description"`
+	Schema        swaggerSchemaObject        `json:"schema"`
+	Examples      map[string]json.RawMessage `json:"examples,omitempty"`
+	NamedExamples swaggerExamplesObject      `json:"x-examples,omitempty"`
 }
 
 type keyVal struct {
@@ -245,6 +347,29 @@ func (op swaggerSchemaObjectProperties) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func (op *swaggerSchemaObjectProperties) UnmarshalJSON(data []byte) error {
+	// decode token by token to keep the order of properties
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := t.(string)
+		var val swaggerSchemaObject
+		if err := dec.Decode(&val); err != nil {
+			return err
+		}
+		*op = append(*op, keyVal{Key: key, Value: val})
+	}
+
+	_, err := dec.Token()
+	return err
+}
+
 // http://swagger.io/specification/#schemaObject
 type swaggerSchemaObject struct {
 	schemaCore
@@ -274,6 +399,59 @@ type swaggerSchemaObject struct {
 	Required         []string              `json:"required,omitempty"`
 	AllOf            []swaggerSchemaObject `json:"allOf,omitempty"`
 	Example          interface{}           `json:"example,omitempty"`
+	// swagger 2.0 does not support the deprecated schema
+	Deprecated bool `json:"x-deprecated,omitempty"`
+
+	Extensions swaggerExtensions `json:"-"`
+}
+
+func (o swaggerSchemaObject) MarshalJSON() ([]byte, error) {
+	type alias swaggerSchemaObject
+	return marshalWithExtensions(alias(o), o.Extensions)
+}
+
+// swaggerExtensions is the vendor extensions whose keys start with "x-",
+// the values are the json encoded values.
+// http://swagger.io/specification/#specificationExtensions
+type swaggerExtensions map[string]json.RawMessage
+
+// marshalWithExtensions marshals the object and appends the extensions to it in key order,
+// the extensions which have the same keys as the fields of the object are ignored.
+func marshalWithExtensions(v interface{}, extensions swaggerExtensions) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for i, key := range keys {
+		if i > 0 || len(fields) > 0 {
+			buf.WriteString(",")
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteString(":")
+		buf.Write(extensions[key])
+	}
+	buf.WriteString("}")
+
+	return buf.Bytes(), nil
 }
 
 // http://swagger.io/specification/#definitionsObject
@@ -282,11 +460,36 @@ type swaggerDefinitionsObject map[string]swaggerSchemaObject
 // Internal type to store used references.
 type refMap map[string]struct{}
 
-// responseField 响应字段
+// responseField 响应字段，除 name、is_data 和 required 外，支持完整的 schema 语法
 type responseField struct {
-	Name        string      `json:"name"`
-	Type        string      `json:"type"`
-	Description string      `json:"description"`
-	IsData      bool        `json:"is_data"`
-	Example     interface{} `json:"example"`
+	Name     string `json:"name"`
+	IsData   bool   `json:"is_data"`
+	Required bool   `json:"required"`
+	swaggerSchemaObject
+}
+
+func (f *responseField) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, dst := range map[string]interface{}{"name": &f.Name, "is_data": &f.IsData} {
+		if v, ok := raw[key]; ok {
+			if err := json.Unmarshal(v, dst); err != nil {
+				return err
+			}
+			delete(raw, key)
+		}
+	}
+	// boolean "required" marks the field itself, otherwise it's the required list of the field schema
+	if v, ok := raw["required"]; ok && json.Unmarshal(v, &f.Required) == nil {
+		delete(raw, "required")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &f.swaggerSchemaObject)
 }
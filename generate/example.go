@@ -0,0 +1,633 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	atDocKeyRequestExample  = "request_example"
+	atDocKeyResponseExample = "response_example"
+	defaultExampleName      = "default"
+
+	// maxExampleDepth limits the depth of the recursive definitions.
+	maxExampleDepth = 32
+	// maxSynthesisDepth limits the depth of the synthesized nested examples.
+	maxSynthesisDepth = 3
+)
+
+var exampleNames = []string{"Alice", "Bob", "Carol", "David", "Eve", "Frank", "Grace", "Heidi"}
+
+// swaggerExampleObject is the named example, which is same as the example object of openapi 3.x.
+// https://spec.openapis.org/oas/v3.0.3#example-object
+type swaggerExampleObject struct {
+	Summary string          `json:"summary,omitempty"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// swaggerExamplesObject is the named examples of the media types,
+// which is output as x-examples since swagger 2.0 does not support the named examples.
+type swaggerExamplesObject map[string]map[string]swaggerExampleObject
+
+// routeExampleSources returns the example sources of the @doc,
+// the key is request_example or response_example with an optional name suffix,
+// and the value is the json file path relative to the api file or the inline json, it's like this below:
+//
+//	@doc (
+//		request_example: "examples/create_order.json"  // named default
+//		request_example_minimal: "{'sku': 'A001'}"     // named minimal, strings use single quotes
+//		response_example: "examples/order.json"
+//	)
+func routeExampleSources(properties map[string]string) map[string]string {
+	var sources map[string]string
+	for key, value := range properties {
+		if strings.HasPrefix(key, atDocKeyRequestExample) || strings.HasPrefix(key, atDocKeyResponseExample) {
+			if sources == nil {
+				sources = make(map[string]string)
+			}
+			sources[key] = strings.TrimSpace(unquote(value))
+		}
+	}
+
+	return sources
+}
+
+// renderExamples loads the request and response examples of the operations,
+// validates them against the schemas, and renders them as x-examples and the response examples.
+func renderExamples(s *swaggerObject, dir string) error {
+	for _, path := range sortedKeys(s.Paths) {
+		item := s.Paths[path]
+		for _, op := range []*swaggerOperationObject{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil || len(op.exampleSources) == 0 {
+				continue
+			}
+			if err := renderOperationExamples(s, op, dir); err != nil {
+				return fmt.Errorf("render examples of operation %s err: %w", op.OperationID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func renderOperationExamples(s *swaggerObject, op *swaggerOperationObject, dir string) error {
+	requests, err := loadExamples(op.exampleSources, atDocKeyRequestExample, dir)
+	if err != nil {
+		return err
+	}
+	responses, err := loadExamples(op.exampleSources, atDocKeyResponseExample, dir)
+	if err != nil {
+		return err
+	}
+
+	if len(requests) > 0 {
+		i := -1
+		for j, param := range op.Parameters {
+			if param.In == "body" && param.Ref == "" {
+				i = j
+			}
+		}
+		if i < 0 {
+			return fmt.Errorf("request examples are declared but there is no body parameter")
+		}
+		param := &op.Parameters[i]
+		for _, name := range sortedKeys(requests) {
+			if err := validateExample(requests[name].Value, param.Schema, s.Definitions); err != nil {
+				return fmt.Errorf("request example %s is invalid: %w", name, err)
+			}
+		}
+		param.Examples = swaggerExamplesObject{exampleMediaType(op.Consumes, s.Consumes): requests}
+	}
+
+	if len(responses) > 0 {
+		resp, ok := op.Responses["200"]
+		if !ok {
+			return fmt.Errorf("response examples are declared but there is no successful response")
+		}
+		for _, name := range sortedKeys(responses) {
+			if err := validateExample(responses[name].Value, &resp.Schema, s.Definitions); err != nil {
+				return fmt.Errorf("response example %s is invalid: %w", name, err)
+			}
+		}
+		mediaType := exampleMediaType(op.Produces, s.Produces)
+		example, ok := responses[defaultExampleName]
+		if !ok {
+			example = responses[sortedKeys(responses)[0]]
+		}
+		// swagger 2.0 only supports one example per media type, the default one is used.
+		resp.Examples = map[string]json.RawMessage{mediaType: example.Value}
+		resp.NamedExamples = swaggerExamplesObject{mediaType: responses}
+		op.Responses["200"] = resp
+	}
+
+	return nil
+}
+
+// loadExamples loads the examples of the sources with the key prefix, the name is the key suffix or default.
+func loadExamples(sources map[string]string, prefix, dir string) (map[string]swaggerExampleObject, error) {
+	var examples map[string]swaggerExampleObject
+	for key, source := range sources {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(key, prefix), "_")
+		if name == "" {
+			name = defaultExampleName
+		}
+
+		value, err := loadExample(source, dir)
+		if err != nil {
+			return nil, fmt.Errorf("load %s err: %w", key, err)
+		}
+		if examples == nil {
+			examples = make(map[string]swaggerExampleObject)
+		}
+		examples[name] = swaggerExampleObject{Value: value}
+	}
+
+	return examples, nil
+}
+
+// loadExample loads the inline json which starts with "{" or "[", or the json file relative to the api file,
+// the strings of the inline json can be quoted by single quotes, but the json file must be the standard json.
+func loadExample(source, dir string) (json.RawMessage, error) {
+	var data []byte
+	if strings.HasPrefix(source, "{") || strings.HasPrefix(source, "[") {
+		data = []byte(source)
+		if v, ok := singleQuotedJSON(source); ok {
+			data = v
+		}
+	} else {
+		filename := source
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(dir, filename)
+		}
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		data = content
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, fmt.Errorf("invalid json %s: %w", source, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// exampleMediaType returns the json media type of the operation, application/json by default.
+func exampleMediaType(operationTypes, defaultTypes []string) string {
+	types := operationTypes
+	if len(types) == 0 {
+		types = defaultTypes
+	}
+	for _, t := range types {
+		if strings.Contains(t, "json") {
+			return t
+		}
+	}
+
+	return mimeJson
+}
+
+// validateExample validates the json example against the schema.
+func validateExample(data json.RawMessage, schema *swaggerSchemaObject, d swaggerDefinitionsObject) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+
+	return validateValue(value, schema, d, "$", 0, false)
+}
+
+// validateValue validates the value against the schema,
+// partial means the schema is a part of allOf, so the properties declared by the other parts are allowed.
+func validateValue(value interface{}, schema *swaggerSchemaObject, d swaggerDefinitionsObject, at string, depth int, partial bool) error {
+	if schema == nil || value == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Ref != "" {
+		def, ok := d[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			return nil
+		}
+		return validateValue(value, &def, d, at, depth+1, partial)
+	}
+	for i := range schema.AllOf {
+		if err := validateValue(value, &schema.AllOf[i], d, at, depth+1, true); err != nil {
+			return err
+		}
+	}
+	if obj, ok := value.(map[string]interface{}); ok && len(schema.AllOf) > 0 && !partial {
+		declared := make(map[string]struct{})
+		if open := collectDeclaredProperties(schema, d, declared, depth); !open {
+			for _, name := range sortedKeys(obj) {
+				if _, ok := declared[name]; !ok {
+					return fmt.Errorf("%s.%s is not declared", at, name)
+				}
+			}
+		}
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s should be an object", at)
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s.%s is required", at, name)
+			}
+		}
+		if schema.Properties == nil {
+			return nil
+		}
+		props := make(map[string]swaggerSchemaObject, len(*schema.Properties))
+		for _, kv := range *schema.Properties {
+			if prop, ok := kv.Value.(swaggerSchemaObject); ok {
+				props[kv.Key] = prop
+			}
+		}
+		for _, name := range sortedKeys(obj) {
+			prop, ok := props[name]
+			if !ok {
+				if schema.AdditionalProperties == nil && !partial {
+					return fmt.Errorf("%s.%s is not declared", at, name)
+				}
+				continue
+			}
+			if err := validateValue(obj[name], &prop, d, at+"."+name, depth+1, false); err != nil {
+				return err
+			}
+		}
+		if schema.AdditionalProperties != nil && len(props) == 0 {
+			for _, name := range sortedKeys(obj) {
+				if err := validateValue(obj[name], schema.AdditionalProperties, d, at+"."+name, depth+1, false); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s should be an array", at)
+		}
+		if schema.Items == nil {
+			return nil
+		}
+		items := swaggerSchemaObject(*schema.Items)
+		for i, v := range arr {
+			if err := validateValue(v, &items, d, fmt.Sprintf("%s[%d]", at, i), depth+1, false); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s should be a string", at)
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, str) {
+			return fmt.Errorf("%s should be one of %v", at, schema.Enum)
+		}
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s should be a number", at)
+		}
+		if _, err := num.Int64(); err != nil && schema.Type == "integer" {
+			return fmt.Errorf("%s should be an integer", at)
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, num.String()) {
+			return fmt.Errorf("%s should be one of %v", at, schema.Enum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s should be a boolean", at)
+		}
+	}
+
+	return nil
+}
+
+// collectDeclaredProperties collects the property names declared by the schema and its allOf parts,
+// and returns true if any part allows the additional properties.
+func collectDeclaredProperties(schema *swaggerSchemaObject, d swaggerDefinitionsObject, declared map[string]struct{}, depth int) bool {
+	if depth > maxExampleDepth {
+		return true
+	}
+	if schema.Ref != "" {
+		def, ok := d[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			return true
+		}
+		return collectDeclaredProperties(&def, d, declared, depth+1)
+	}
+
+	open := schema.AdditionalProperties != nil
+	if schema.Properties != nil {
+		for _, kv := range *schema.Properties {
+			declared[kv.Key] = struct{}{}
+		}
+	}
+	for i := range schema.AllOf {
+		if collectDeclaredProperties(&schema.AllOf[i], d, declared, depth+1) {
+			open = true
+		}
+	}
+
+	return open
+}
+
+// sortedKeys returns the sorted keys of the map.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// exampleSynthesizer synthesizes the examples from the schemas,
+// the random values of each field are generated by its own source seeded by the seed and the field key,
+// so the examples are stable across runs and not affected by the other fields.
+type exampleSynthesizer struct {
+	seed int64
+	d    swaggerDefinitionsObject
+}
+
+// synthesizeExamples synthesizes the examples of the definition properties and the non-body parameters
+// which have no examples.
+func synthesizeExamples(s *swaggerObject, seed int64) {
+	g := &exampleSynthesizer{seed: seed, d: s.Definitions}
+
+	for _, name := range sortedKeys(s.Definitions) {
+		schema := s.Definitions[name]
+		g.fillProperties(name, &schema)
+		s.Definitions[name] = schema
+	}
+
+	for _, name := range sortedKeys(s.Parameters) {
+		param := s.Parameters[name]
+		g.fillParameter(name, &param)
+		s.Parameters[name] = param
+	}
+	for _, path := range sortedKeys(s.Paths) {
+		item := s.Paths[path]
+		for _, op := range []*swaggerOperationObject{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil {
+				continue
+			}
+			for i := range op.Parameters {
+				g.fillParameter(op.OperationID+"."+op.Parameters[i].Name, &op.Parameters[i])
+			}
+		}
+	}
+}
+
+// fillProperties fills the examples of the properties recursively.
+func (g *exampleSynthesizer) fillProperties(key string, schema *swaggerSchemaObject) {
+	if schema.Properties == nil {
+		return
+	}
+	for i, kv := range *schema.Properties {
+		prop, ok := kv.Value.(swaggerSchemaObject)
+		if !ok {
+			continue
+		}
+		g.fillProperties(key+"."+kv.Key, &prop)
+		if prop.Example == nil && prop.Ref == "" {
+			prop.Example = g.value(key+"."+kv.Key, kv.Key, &prop, 0)
+		}
+		(*schema.Properties)[i].Value = prop
+	}
+}
+
+// fillParameter fills the example of the non-body parameter.
+func (g *exampleSynthesizer) fillParameter(key string, param *swaggerParameterObject) {
+	if param.Ref != "" || param.In == "body" || param.Example != "" {
+		return
+	}
+	schema := &swaggerSchemaObject{
+		schemaCore: schemaCore{Type: param.Type, Format: param.Format, Enum: param.Enum},
+		Minimum:    param.Minimum, Maximum: param.Maximum,
+		MinLength: param.MinLength, MaxLength: param.MaxLength,
+	}
+	if param.Items != nil {
+		schema.Items = param.Items
+	}
+	switch v := g.value(key, param.Name, schema, 0).(type) {
+	case nil:
+	case string:
+		param.Example = v
+	default:
+		data, _ := json.Marshal(v)
+		param.Example = string(data)
+	}
+}
+
+// value returns the example value of the schema, name is the field name used by the heuristics.
+func (g *exampleSynthesizer) value(key, name string, schema *swaggerSchemaObject, depth int) interface{} {
+	if depth > maxSynthesisDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Ref != "" {
+		def, ok := g.d[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			return nil
+		}
+		return g.value(key, name, &def, depth+1)
+	}
+
+	r := rand.New(rand.NewSource(g.seed ^ int64(hashKey(key))))
+	if len(schema.Enum) > 0 {
+		return enumExample(schema.Type, schema.Enum[r.Intn(len(schema.Enum))])
+	}
+
+	switch schema.Type {
+	case "object", "":
+		if schema.Properties != nil {
+			props := make(swaggerSchemaObjectProperties, 0, len(*schema.Properties))
+			for _, kv := range *schema.Properties {
+				prop, ok := kv.Value.(swaggerSchemaObject)
+				if !ok {
+					continue
+				}
+				if v := g.value(key+"."+kv.Key, kv.Key, &prop, depth+1); v != nil {
+					props = append(props, keyVal{Key: kv.Key, Value: v})
+				}
+			}
+			return props
+		}
+		if schema.AdditionalProperties != nil {
+			if v := g.value(key+".key", name, schema.AdditionalProperties, depth+1); v != nil {
+				return swaggerSchemaObjectProperties{{Key: "key", Value: v}}
+			}
+		}
+		if schema.Type == "object" {
+			return swaggerSchemaObjectProperties{}
+		}
+		return nil
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		items := swaggerSchemaObject(*schema.Items)
+		if v := g.value(key+"[]", name, &items, depth+1); v != nil {
+			return []interface{}{v}
+		}
+		return []interface{}{}
+	case "string":
+		return stringExample(r, name, schema)
+	case "integer":
+		return int64(numberExample(r, name, schema))
+	case "number":
+		return float64(int64(numberExample(r, name, schema)*100)) / 100
+	case "boolean":
+		return true
+	}
+
+	return nil
+}
+
+// stringExample returns the string example by the format and the field name.
+func stringExample(r *rand.Rand, name string, schema *swaggerSchemaObject) string {
+	n := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	var v string
+	switch {
+	case schema.Format == "uuid" || strings.HasSuffix(n, "uuid"):
+		b := make([]byte, 16)
+		r.Read(b)
+		v = fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case schema.Format == "email" || strings.Contains(n, "email"):
+		v = exampleNames[r.Intn(len(exampleNames))] + "@example.com"
+		v = strings.ToLower(v)
+	case schema.Format == "uri" || hasWordSuffix(name, "url") || hasWordSuffix(name, "link") ||
+		strings.Contains(n, "avatar") || strings.Contains(n, "image"):
+		v = fmt.Sprintf("https://example.com/%s/%d", strings.ToLower(name), r.Intn(1000))
+	case schema.Format == "hostname" || strings.Contains(n, "host") || strings.Contains(n, "domain"):
+		v = "api.example.com"
+	case schema.Format == "ipv4" || hasWordSuffix(name, "ip"):
+		v = fmt.Sprintf("192.168.%d.%d", r.Intn(256), 1+r.Intn(254))
+	case schema.Format == "ipv6":
+		v = fmt.Sprintf("2001:db8::%x", 1+r.Intn(0xffff))
+	case schema.Format == "date-time" || isTimeField(name):
+		v = exampleTime(r).Format(time.RFC3339)
+	case schema.Format == "date" || strings.HasSuffix(n, "date") || strings.Contains(n, "birthday"):
+		v = exampleTime(r).Format("2006-01-02")
+	case strings.Contains(n, "phone") || strings.Contains(n, "mobile"):
+		v = fmt.Sprintf("138%08d", r.Intn(100000000))
+	case strings.HasSuffix(n, "name"):
+		v = exampleNames[r.Intn(len(exampleNames))]
+	case hasWordSuffix(name, "id") || strings.Contains(n, "token") || strings.Contains(n, "code"):
+		v = fmt.Sprintf("%x", r.Uint64())
+	default:
+		v = strings.ToLower(name)
+		if v == "" {
+			v = "string"
+		}
+	}
+
+	if schema.MaxLength > 0 && uint64(len(v)) > schema.MaxLength {
+		v = v[:schema.MaxLength]
+	}
+	for uint64(len(v)) < schema.MinLength {
+		v += "x"
+	}
+
+	return v
+}
+
+// numberExample returns the number example in the range by the field name.
+func numberExample(r *rand.Rand, name string, schema *swaggerSchemaObject) float64 {
+	n := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	min, max := float64(1), float64(1000)
+	switch {
+	case n == "page":
+		min, max = 1, 1
+	case strings.HasSuffix(n, "size") || strings.HasSuffix(n, "limit"):
+		min, max = 10, 10
+	case isTimeField(name):
+		t := float64(exampleTime(r).Unix())
+		min, max = t, t
+	case hasWordSuffix(name, "age"):
+		min, max = 18, 60
+	}
+
+	if schema.Minimum != 0 || schema.Maximum != 0 {
+		min, max = schema.Minimum, schema.Maximum
+		if schema.ExclusiveMinimum {
+			min++
+		}
+		if schema.ExclusiveMaximum {
+			max--
+		}
+		if max < min {
+			max = min
+		}
+	}
+
+	return min + r.Float64()*(max-min)
+}
+
+// enumExample returns the enum value of the type.
+func enumExample(typ, value string) interface{} {
+	switch typ {
+	case "integer":
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+
+	return value
+}
+
+// hasWordSuffix returns true if the last word of the snake or camel case name is the lower case word.
+func hasWordSuffix(name, word string) bool {
+	lower := strings.ToLower(name)
+	return lower == word || strings.HasSuffix(lower, "_"+word) ||
+		strings.HasSuffix(name, strings.ToUpper(word[:1])+word[1:]) || strings.HasSuffix(name, strings.ToUpper(word))
+}
+
+// isTimeField returns true if the name is like created_at, updatedAt or start_time.
+func isTimeField(name string) bool {
+	return hasWordSuffix(name, "at") || hasWordSuffix(name, "time") || hasWordSuffix(name, "timestamp")
+}
+
+// exampleTime returns the example time in 2024.
+func exampleTime(r *rand.Rand) time.Time {
+	return time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(r.Int63n(365*24*3600)) * time.Second)
+}
+
+// hashKey returns the fnv hash of the key.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
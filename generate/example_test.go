@@ -0,0 +1,234 @@
+package generate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadExample(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"note.json":   `{"text": "it's"}`,
+		"single.json": `{'text': 'a'}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{source: `{"sku": "A001"}`, want: `{"sku":"A001"}`},
+		{source: `{'sku': 'A001'}`, want: `{"sku":"A001"}`},
+		{source: `{'text': 'it\'s'}`, want: `{"text":"it's"}`},
+		{source: `["it's"]`, want: `["it's"]`},
+		{source: `{'text': 'it's'}`, wantErr: true},
+		{source: "note.json", want: `{"text":"it's"}`},
+		{source: "single.json", wantErr: true},
+		{source: "missing.json", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.source, func(t *testing.T) {
+			got, err := loadExample(tc.source, dir)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+const exampleAPI = `syntax = "v1"
+
+type (
+	CreateReq {
+		Sku   string ` + "`json:\"sku\"`" + `
+		Count int    ` + "`json:\"count,optional\"`" + `
+		Kind  string ` + "`json:\"kind,optional,options=a|b\"`" + `
+	}
+	Order {
+		Id   int64    ` + "`json:\"id\"`" + `
+		Tags []string ` + "`json:\"tags,optional\"`" + `
+	}
+)
+
+service demo {
+	@doc (
+		request_example: "{{request}}"
+		response_example: "examples/order.json"
+	)
+	@handler create
+	post /orders (CreateReq) returns (Order)
+}`
+
+func TestRenderExamples(t *testing.T) {
+	cases := []struct {
+		name     string
+		request  string
+		response string
+		pack     bool
+		wantErr  string
+	}{
+		{name: "valid", request: "{'sku': 'A001', 'count': 2, 'kind': 'a'}", response: `{"id": 1, "tags": ["it's"]}`},
+		{name: "valid packed", request: "{'sku': 'A001'}", response: `{"code": 0, "msg": "ok", "data": {"id": 1}}`, pack: true},
+		{name: "missing required", request: "{'count': 2}", response: `{"id": 1}`, wantErr: "$.sku is required"},
+		{name: "wrong type", request: "{'sku': 'A001', 'count': '2'}", response: `{"id": 1}`, wantErr: "$.count should be a number"},
+		{name: "not in enum", request: "{'sku': 'A001', 'kind': 'c'}", response: `{"id": 1}`, wantErr: "$.kind should be one of"},
+		{name: "undeclared", request: "{'sku': 'A001', 'extra': 1}", response: `{"id": 1}`, wantErr: "$.extra is not declared"},
+		{name: "wrong items", request: "{'sku': 'A001'}", response: `{"id": 1, "tags": [1]}`, wantErr: "$.tags[0] should be a string"},
+		{name: "packed undeclared", request: "{'sku': 'A001'}", response: `{"code": 0, "extra": 1, "data": {"id": 1}}`, pack: true, wantErr: "$.extra is not declared"},
+		{name: "packed data", request: "{'sku': 'A001'}", response: `{"code": 0, "data": {"tags": []}}`, pack: true, wantErr: "$.data.id is required"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := strings.Replace(exampleAPI, "{{request}}", tc.request, 1)
+			p := newTestPlugin(t, api, map[string]string{"examples/order.json": tc.response})
+			c := &Config{}
+			if tc.pack {
+				c.Pack = "Response"
+			}
+			s, err := applyGenerate(p, c)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want error with %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := decodeJSON(t, s)
+			op := lookup(got, "paths", "/orders", "post")
+			params, _ := lookup(op, "parameters").([]interface{})
+			if len(params) != 1 || lookup(params[0], "x-examples", "application/json", "default", "value", "sku") != "A001" {
+				t.Errorf("want the request example of the body parameter, got %v", params)
+			}
+			if lookup(op, "responses", "200", "examples", "application/json") == nil {
+				t.Errorf("want the response example, got %v", lookup(op, "responses", "200"))
+			}
+			if lookup(op, "responses", "200", "x-examples", "application/json", "default", "value") == nil {
+				t.Errorf("want the named response example, got %v", lookup(op, "responses", "200"))
+			}
+		})
+	}
+}
+
+const synthesisAPI = `syntax = "v1"
+
+type (
+	User {
+		Id        int64   ` + "`json:\"id\"`" + `
+		Email     string  ` + "`json:\"email\"`" + `
+		Nickname  string  ` + "`json:\"nickname\" example:\"neo\"`" + `
+		Score     int     ` + "`json:\"score,range=[60:100]\"`" + `
+		Status    string  ` + "`json:\"status,options=active|banned\"`" + `
+		CreatedAt string  ` + "`json:\"created_at\"`" + `
+		Homepage  string  ` + "`json:\"homepage_url\"`" + `
+		Friends   []Brief ` + "`json:\"friends\"`" + `
+	}
+	Brief {
+		Id   int64  ` + "`json:\"id\"`" + `
+		Name string ` + "`json:\"name\"`" + `
+	}
+	ListReq {
+		Page int ` + "`form:\"page\"`" + `
+		Size int ` + "`form:\"size\"`" + `
+	}
+)
+
+service demo {
+	@handler list
+	get /users (ListReq) returns (User)
+}`
+
+func TestSynthesizeExamples(t *testing.T) {
+	generate := func(seed int64) map[string]interface{} {
+		s, err := generateJSON(t, synthesisAPI, &Config{SynthesizeExamples: true, ExampleSeed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s := generate(1)
+	example := func(prop string) interface{} {
+		return lookup(s, "definitions", "User", "properties", prop, "example")
+	}
+
+	if email, _ := example("email").(string); !strings.HasSuffix(email, "@example.com") {
+		t.Errorf("want the email example, got %v", example("email"))
+	}
+	if example("nickname") != "neo" {
+		t.Errorf("want the declared example kept, got %v", example("nickname"))
+	}
+	if score, _ := example("score").(float64); score < 60 || score > 100 {
+		t.Errorf("want the score example in range, got %v", example("score"))
+	}
+	if status := example("status"); status != "active" && status != "banned" {
+		t.Errorf("want the status example in options, got %v", status)
+	}
+	if created, _ := example("created_at").(string); !strings.HasPrefix(created, "2024-") {
+		t.Errorf("want the time example, got %v", example("created_at"))
+	}
+	if url, _ := example("homepage_url").(string); !strings.HasPrefix(url, "https://example.com/") {
+		t.Errorf("want the url example, got %v", example("homepage_url"))
+	}
+	if friends, _ := example("friends").([]interface{}); len(friends) != 1 || lookup(friends[0], "name") == nil {
+		t.Errorf("want the array example of the referred items, got %v", example("friends"))
+	}
+
+	ins := map[string]string{}
+	params, _ := lookup(s, "paths", "/users", "get", "parameters").([]interface{})
+	for _, p := range params {
+		name, _ := lookup(p, "name").(string)
+		ins[name], _ = lookup(p, "example").(string)
+	}
+	if ins["page"] != "1" || ins["size"] != "10" {
+		t.Errorf("want the page and size examples, got %v", ins)
+	}
+
+	// the examples of the definitions are valid against their schemas.
+	api, err := applyGenerate(newTestPlugin(t, synthesisAPI, nil), &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"User", "Brief"} {
+		props, _ := lookup(s, "definitions", name, "properties").(map[string]interface{})
+		value := make(map[string]interface{}, len(props))
+		for prop := range props {
+			value[prop] = lookup(props, prop, "example")
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		def := api.Definitions[name]
+		if err := validateExample(data, &def, api.Definitions); err != nil {
+			t.Errorf("synthesized example of %s is invalid: %v\n%s", name, err, data)
+		}
+	}
+
+	// the examples are stable with the same seed, and vary with the seed.
+	if !reflect.DeepEqual(generate(1), s) {
+		t.Error("want the same examples with the same seed")
+	}
+	if reflect.DeepEqual(generate(2), s) {
+		t.Error("want the different examples with the different seed")
+	}
+}
@@ -1,33 +1,105 @@
 package generate
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/zeromicro/go-zero/tools/goctl/plugin"
 )
 
-// Do generates the swagger json doc.
-func Do(filename, host, basePath, schemes, pack, response string, in *plugin.Plugin) error {
-	swagger, err := applyGenerate(in, host, basePath, schemes, pack, response)
+const defaultFilename = "rest.swagger.json"
+
+// Do generates the swagger json doc, the file name is rest.swagger.json by default,
+// it's the base name of the documents if per is specified, e.g. api.swagger.json for api-v1.swagger.json.
+// all the documents are generated before writing, so no file is written if any of them fails.
+func Do(filename string, c *Config, in *plugin.Plugin) error {
+	if c.Per == "" {
+		if filename == "" {
+			filename = defaultFilename
+		}
+		_, files, err := render(filename, c, in, "")
+		if err != nil {
+			return err
+		}
+		return writeFiles(in.Dir, files)
+	}
+	if c.Split != "" {
+		return fmt.Errorf("split can not be used with per: %s", c.Per)
+	}
+
+	docs, err := partitionService(in.Api.Service, c.Per)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
-	var formatted bytes.Buffer
-	enc := json.NewEncoder(&formatted)
-	enc.SetIndent("", "  ")
+	// the file name option takes precedence over the file name template of the config.
+	tmpl := c.FilenameTemplate
+	if filename != "" {
+		tmpl = filenameTemplate(filename)
+	}
+	if tmpl == "" {
+		tmpl = defaultFilenameTemplate
+	}
+	files := make(map[string][]byte)
+	var unused []string
+	for i, doc := range docs {
+		api := *in.Api
+		api.Service = doc.service
+		p := *in
+		p.Api = &api
 
-	if err := enc.Encode(swagger); err != nil {
-		fmt.Println(err)
+		version := c.Versions[doc.name]
+		if version == "" && c.Per == perVersion && doc.name != defaultDocumentName {
+			version = doc.name
+		}
+		swagger, docFiles, err := render(documentFilename(tmpl, in.Api.Service.Name, doc.name), c, &p, version)
+		if err != nil {
+			return err
+		}
+		for name, data := range docFiles {
+			files[name] = data
+		}
+		// the types are unused by the whole service only if they are unused by all the documents.
+		if i == 0 {
+			unused = swagger.unusedTypes
+			continue
+		}
+		var unusedByAll []string
+		for _, name := range unused {
+			if contains(swagger.unusedTypes, name) {
+				unusedByAll = append(unusedByAll, name)
+			}
+		}
+		unused = unusedByAll
+	}
+	if c.PruneDefinitions {
+		reportUnusedTypes(unused)
 	}
 
-	output := in.Dir + "/" + filename
+	return writeFiles(in.Dir, files)
+}
 
-	err = os.WriteFile(output, formatted.Bytes(), 0o666)
+// render generates the swagger json doc of the plugin api and returns it with the files to write by name,
+// version overrides the info version if it's not empty.
+func render(filename string, c *Config, in *plugin.Plugin, version string) (*swaggerObject, map[string][]byte, error) {
+	swagger, err := applyGenerate(in, c)
 	if err != nil {
-		fmt.Println(err)
+		return nil, nil, err
+	}
+	if version != "" {
+		swagger.Info.Version = version
+	}
+
+	var files map[string][]byte
+	if c.Split != "" {
+		files, err = splitSwagger(swagger, filename, c)
+	} else {
+		var data []byte
+		data, err = json.Marshal(swagger)
+		files = map[string][]byte{filename: data}
 	}
-	return err
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return swagger, files, nil
 }
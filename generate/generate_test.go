@@ -0,0 +1,310 @@
+package generate
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeromicro/go-zero/tools/goctl/api/parser"
+	"github.com/zeromicro/go-zero/tools/goctl/plugin"
+)
+
+// newTestPlugin parses the api content in a temporary directory, the extra files are written beside the api file.
+func newTestPlugin(t *testing.T, api string, files map[string]string) *plugin.Plugin {
+	t.Helper()
+
+	dir := t.TempDir()
+	apiFile := filepath.Join(dir, "test.api")
+	if err := os.WriteFile(apiFile, []byte(api), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec, err := parser.Parse(apiFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &plugin.Plugin{Api: spec, ApiFilePath: apiFile, Dir: dir}
+}
+
+// generateJSON generates the swagger json doc of the api content and decodes it for the assertions.
+func generateJSON(t *testing.T, api string, c *Config) (map[string]interface{}, error) {
+	t.Helper()
+
+	if c == nil {
+		c = &Config{}
+	}
+	s, err := applyGenerate(newTestPlugin(t, api, nil), c)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(t, s), nil
+}
+
+// decodeJSON marshals the value and decodes it as the generic json value.
+func decodeJSON(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+// lookup returns the value of the json value by the keys, e.g. "paths", "/a", "get".
+func lookup(v interface{}, path ...string) interface{} {
+	for _, key := range path {
+		switch o := v.(type) {
+		case map[string]interface{}:
+			v = o[key]
+		default:
+			return nil
+		}
+	}
+
+	return v
+}
+
+// parameterIns returns the locations of the operation parameters by name.
+func parameterIns(op interface{}) map[string]string {
+	ins := make(map[string]string)
+	params, _ := lookup(op, "parameters").([]interface{})
+	for _, p := range params {
+		param, _ := p.(map[string]interface{})
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		ins[name] = in
+	}
+
+	return ins
+}
+
+func TestDoPerFilename(t *testing.T) {
+	const api = `syntax = "v1"
+
+@server (
+	prefix: /v1
+)
+service demo {
+	@handler listV1
+	get /orders
+}
+
+@server (
+	prefix: /v2
+)
+service demo {
+	@handler listV2
+	get /orders
+}`
+
+	cases := []struct {
+		name     string
+		filename string
+		template string
+		want     []string
+	}{
+		{name: "default", want: []string{"demo-v1.swagger.json", "demo-v2.swagger.json"}},
+		{name: "template", template: "{version}/{service}.json", want: []string{"v1/demo.json", "v2/demo.json"}},
+		{name: "filename", filename: "api.swagger.json", want: []string{"api-v1.swagger.json", "api-v2.swagger.json"}},
+		{name: "filename over template", filename: "api.json", template: "{service}.{name}.json", want: []string{"api-v1.json", "api-v2.json"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, api, nil)
+			if err := Do(tc.filename, &Config{Per: perVersion, FilenameTemplate: tc.template}, p); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tc.want {
+				if _, err := os.Stat(filepath.Join(p.Dir, name)); err != nil {
+					t.Errorf("want document %s: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDoInvalid(t *testing.T) {
+	const api = `syntax = "v1"
+
+service demo {
+	@handler ping
+	get /ping
+}`
+
+	cases := []struct {
+		name string
+		c    *Config
+	}{
+		{name: "unsupported form_in", c: &Config{FormIn: "header"}},
+		{name: "unsupported sort", c: &Config{Sort: "random"}},
+		{name: "unsupported per", c: &Config{Per: "tag"}},
+		{name: "unsupported scheme", c: &Config{Schemes: "http,ftp"}},
+		{name: "unsupported operationId", c: &Config{OperationID: "group-handler"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, api, nil)
+			if err := Do("", tc.c, p); err == nil {
+				t.Fatal("want error")
+			}
+			entries, err := os.ReadDir(p.Dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Name() != "test.api" {
+					t.Errorf("want no output, got %s", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestDoPerInvalidDocument(t *testing.T) {
+	const api = `syntax = "v1"
+
+@server (
+	group: order
+)
+service demo {
+	@handler listOrders
+	get /orders
+}
+
+@server (
+	group: user
+)
+service demo {
+	@doc (
+		form_in: "header"
+	)
+	@handler login
+	post /login
+}`
+
+	p := newTestPlugin(t, api, nil)
+	if err := Do("", &Config{Per: perGroup}, p); err == nil {
+		t.Fatal("want error")
+	}
+	// the valid document of the order group is not written either.
+	entries, err := os.ReadDir(p.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "test.api" {
+			t.Errorf("want no output, got %s", entry.Name())
+		}
+	}
+}
+
+// captureStderr returns what is written to the stderr by f.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	f()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestDoUnusedTypes(t *testing.T) {
+	const api = `syntax = "v1"
+
+type (
+	Common {
+		Tenant string ` + "`header:\"X-Tenant\"`" + `
+	}
+	GetReq {
+		Common
+		Id int64 ` + "`path:\"id\"`" + `
+	}
+	ListReq {
+		Page int ` + "`form:\"page\"`" + `
+	}
+	UploadReq {
+		Name string ` + "`form:\"name\"`" + `
+	}
+	Order {
+		Id int64 ` + "`json:\"id\"`" + `
+	}
+	Unused {
+		Id int64 ` + "`json:\"id\"`" + `
+	}
+)
+
+@server (
+	group: order
+)
+service demo {
+	@handler getOrder
+	get /orders/:id (GetReq) returns (Order)
+
+	@handler listOrders
+	get /orders (ListReq) returns ([]Order)
+}
+
+@server (
+	group: file
+)
+service demo {
+	@handler upload
+	post /files (UploadReq)
+}`
+
+	const want = "goctl-swagger: warning: api types are not used by any route and are pruned: Unused\n"
+	cases := []struct {
+		name string
+		c    *Config
+	}{
+		{name: "single", c: &Config{PruneDefinitions: true}},
+		{name: "per group", c: &Config{PruneDefinitions: true, Per: perGroup}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, api, nil)
+			var err error
+			got := captureStderr(t, func() {
+				err = Do("", tc.c, p)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("want report %q, got %q", want, got)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	pathpkg "path"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -23,6 +26,31 @@ import (
 var (
 	strColon        = []byte(":")
 	defaultResponse = parseDefaultResponse()
+
+	supportedSchemes     = []string{"http", "https", "ws", "wss"}
+	serverVariableRegexp = regexp.MustCompile(`\{[^{}]+\}`)
+	versionSegmentRegexp = regexp.MustCompile(`^v\d+(\.\d+)*$`)
+	sunsetDateRegexp     = regexp.MustCompile(`\d{4}-\d{2}(-\d{2})?`)
+	validateFormats      = map[string]string{
+		"uuid":     "uuid",
+		"uuid3":    "uuid",
+		"uuid4":    "uuid",
+		"uuid5":    "uuid",
+		"email":    "email",
+		"url":      "uri",
+		"uri":      "uri",
+		"hostname": "hostname",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+	}
+	collectionFormats = []string{collectionFormatCsv, "ssv", "tsv", "pipes", collectionFormatMulti}
+	mediaTypeAliases  = map[string]string{
+		"json":      mimeJson,
+		"form":      mimeForm,
+		"multipart": mimeMultipart,
+	}
+	// operationIDPlaceholders are the placeholders of the custom operationId template.
+	operationIDPlaceholders = []string{"{group}", "{handler}", "{method}", "{path}"}
 )
 
 const (
@@ -38,12 +66,83 @@ const (
 	atRespDoc       = "@respdoc-"
 
 	tagKeyHeader   = "header"
+	tagKeyCookie   = "cookie"
 	tagKeyPath     = "path"
 	tagKeyForm     = "form"
 	tagKeyJson     = "json"
 	tagKeyValidate = "validate"
 	tagKeyExample  = "example"
 
+	atDocKeyStream          = "stream"
+	atDocKeyPack            = "pack"
+	atDocKeyConsumes        = "consumes"
+	annotationKeySSE        = "sse"
+	annotationKeyPack       = "pack"
+	annotationKeyConsumes   = "consumes"
+	annotationKeyCookieAuth = "cookie_auth"
+	annotationKeyFormIn     = "form_in"
+	annotationKeyGroup      = "group"
+	annotationKeySwtags     = "swtags"
+	annotationKeySummary    = "summary"
+	atDocKeyTags            = "tags"
+	atDocKeyOperationID     = "operationId"
+	atDocKeyDeprecated      = "deprecated"
+	perGroup                = "group"
+	perPrefix               = "prefix"
+	perVersion              = "version"
+	defaultDocumentName     = "default"
+	tagNamingNested         = "nested"
+	tagNamingPlain          = "plain"
+	defaultFilenameTemplate = "{service}-{name}.swagger.json"
+	swaggerExt              = ".swagger.json"
+	selectorGroup           = "group"
+	selectorPrefix          = "prefix"
+	selectorTag             = "tag"
+	selectorHandler         = "handler"
+	selectorInternal        = "internal"
+	atDocKeySummary         = "summary"
+	atDocKeyDescription     = "description"
+	extensionPrefix         = "x-"
+	extensionKeyPrefix      = "x_"
+	infoKeyTitle            = "title"
+	infoKeyVersion          = "version"
+	infoKeyDesc             = "desc"
+	infoKeyAuthor           = "author"
+	infoKeyEmail            = "email"
+	infoKeyContactURL       = "contact_url"
+	infoKeyLicense          = "license"
+	infoKeyLicenseURL       = "license_url"
+	infoKeyTerms            = "terms"
+	infoKeyDocsURL          = "docs_url"
+	infoKeyDocsDesc         = "docs_desc"
+	atDocKeySunset          = "sunset"
+	deprecatedOption        = "deprecated"
+	deprecatedPrefix        = "Deprecated:"
+	operationIDHandler      = "handler"
+	operationIDGroupHandler = "group_handler"
+	operationIDMethodPath   = "method_path"
+	atDocKeyFormIn          = "form_in"
+	formInQuery             = "query"
+	formInBody              = "body"
+	cookieSecurityPrefix    = "cookie_"
+	packNone                = "none"
+	atDocCookiePrefix       = "cookie_"
+	atDocFilePrefix         = "file_"
+	atDocFileArrayPrefix    = "file_array_"
+	fileOptionMime          = "mime"
+	fileOptionMaxSize       = "max_size"
+	fileOptionMinCount      = "min_count"
+	fileOptionMaxCount      = "max_count"
+
+	atDocKeyCollectionFormat = "collection_format"
+	collectionFormatCsv      = "csv"
+	collectionFormatMulti    = "multi"
+	mimeJson                 = "application/json"
+	mimeForm                 = "application/x-www-form-urlencoded"
+	mimeMultipart            = "multipart/form-data"
+	mimeEventStream          = "text/event-stream"
+	streamRefPrefix          = "#/x-stream-definitions/"
+
 	// DefaultResponseJson default response pack json structure.
 	DefaultResponseJson = `[{"name":"trace_id","type":"string","description":"链路追踪id","example":"a1b2c3d4e5f6g7h8"},{"name":"code","type":"integer","description":"状态码","example":0},{"name":"msg","type":"string","description":"消息","example":"ok"},{"name":"data","type":"object","description":"数据","is_data":true}]`
 )
@@ -72,10 +171,8 @@ func parseRangeOption(option string) (min, max float64, ok bool) {
 	return min, max, true
 }
 
-func applyGenerate(p *plugin.Plugin, host, basePath, schemes, pack, response string) (*swaggerObject, error) {
-	title, _ := strconv.Unquote(p.Api.Info.Properties["title"])
-	version, _ := strconv.Unquote(p.Api.Info.Properties["version"])
-	desc, _ := strconv.Unquote(p.Api.Info.Properties["desc"])
+func applyGenerate(p *plugin.Plugin, c *Config) (*swaggerObject, error) {
+	host, basePath, schemes := c.Host, c.BasePath, c.Schemes
 
 	s := swaggerObject{
 		Swagger:           "2.0",
@@ -85,11 +182,13 @@ func applyGenerate(p *plugin.Plugin, host, basePath, schemes, pack, response str
 		Paths:             make(swaggerPathsObject),
 		Definitions:       make(swaggerDefinitionsObject),
 		StreamDefinitions: make(swaggerDefinitionsObject),
-		Info: swaggerInfoObject{
-			Title:       title,
-			Version:     version,
-			Description: desc,
-		},
+		Parameters:        make(swaggerParameterDefinitionsObject),
+	}
+	if err := renderInfo(&s, p.Api.Info, c); err != nil {
+		return nil, err
+	}
+	if err := renderServers(&s, c); err != nil {
+		return nil, err
 	}
 	if len(host) > 0 {
 		s.Host = host
@@ -99,13 +198,12 @@ func applyGenerate(p *plugin.Plugin, host, basePath, schemes, pack, response str
 	}
 
 	if len(schemes) > 0 {
-		supportedSchemes := []string{"http", "https", "ws", "wss"}
 		ss := strings.Split(schemes, ",")
 		for i := range ss {
 			scheme := ss[i]
 			scheme = strings.TrimSpace(scheme)
 			if !contains(supportedSchemes, scheme) {
-				log.Fatalf("unsupport scheme: [%s], only support [http, https, ws, wss]", scheme)
+				return nil, fmt.Errorf("unsupport scheme: [%s], only support [http, https, ws, wss]", scheme)
 			}
 			ss[i] = scheme
 		}
@@ -121,36 +219,105 @@ func applyGenerate(p *plugin.Plugin, host, basePath, schemes, pack, response str
 
 	// s.Security = append(s.Security, swaggerSecurityRequirementObject{"apiKey": []string{}})
 
-	dataKey := "data"
-	if pack != "" {
-		resp := defaultResponse
-		if response != "" {
-			r, dk, err := parseResponse(response)
-			if err != nil {
-				return nil, err
+	if c.TagNaming != "" && c.TagNaming != tagNamingNested && c.TagNaming != tagNamingPlain {
+		return nil, fmt.Errorf("unsupported tag naming: %s, only support [%s %s]", c.TagNaming, tagNamingNested, tagNamingPlain)
+	}
+	if err := checkOperationID(c.OperationID); err != nil {
+		return nil, err
+	}
+	service, err := filterService(p.Api.Service, c.Include, c.Exclude, c.TagNaming)
+	if err != nil {
+		return nil, err
+	}
+
+	// swagger 2.0 does not support the cookie apiKey, so it's described as the Cookie header.
+	for _, group := range service.Groups {
+		if name := group.GetAnnotation(annotationKeyCookieAuth); name != "" {
+			s.SecurityDefinitions[cookieSecurityPrefix+name] = swaggerSecuritySchemeObject{
+				Type:        "apiKey",
+				Name:        "Cookie",
+				In:          "header",
+				Description: fmt.Sprintf("Cookie based authentication, enter `%s=<value>` as the Cookie header", name),
+			}
+		}
+	}
+
+	dataKeys, err := renderPacks(s.Definitions, c, p.Api.Types)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range service.Groups {
+		for _, route := range group.Routes {
+			if pack := routePack(group, route, c.Pack); pack != "" {
+				if _, ok := dataKeys[pack]; !ok {
+					return nil, fmt.Errorf("undefined response pack: %s, route: %s %s", pack, route.Method, route.Path)
+				}
+			}
+			if in := routeFormIn(group, route, c.FormIn); in != "" && in != formInQuery && in != formInBody {
+				return nil, fmt.Errorf("unsupported form_in: %s, route: %s %s, only support [query body]",
+					in, route.Method, route.Path)
+			}
+			if cf := routeCollectionFormat(route, c.CollectionFormat); !contains(collectionFormats, cf) {
+				return nil, fmt.Errorf("unsupported collection format: %s, route: %s %s, only support %v",
+					cf, route.Method, route.Path, collectionFormats)
 			}
-			resp = r
-			dataKey = dk
 		}
-		s.Definitions[pack] = resp
+	}
+
+	for _, name := range sortedKeys(c.Parameters) {
+		raw := c.Parameters[name]
+		var param swaggerParameterObject
+		if err := json.Unmarshal(raw, &param); err != nil {
+			return nil, fmt.Errorf("parse parameter %s err: %w", name, err)
+		}
+		s.Parameters[name] = param
+	}
+
+	if err := renderTags(&s, service, c); err != nil {
+		return nil, err
 	}
 
 	requestResponseRefs := refMap{}
-	renderServiceRoutes(p.Api.Service, p.Api.Service.Groups, s.Paths, requestResponseRefs, pack, dataKey)
-	renderReplyAsDefinition(s.Definitions, p.Api.Types, requestResponseRefs)
+	formLocations := make(map[string]string)
+	if err := renderServiceRoutes(&s, service, service.Groups, requestResponseRefs, formLocations, c, dataKeys); err != nil {
+		return nil, err
+	}
+	renderReplyAsDefinition(s.Definitions, p.Api.Types, requestResponseRefs, formLocations)
+	if c.SynthesizeExamples {
+		synthesizeExamples(&s, c.ExampleSeed)
+	}
+	if err := renderExamples(&s, filepath.Dir(p.ApiFilePath)); err != nil {
+		return nil, err
+	}
+	// the definitions of the filtered out routes and the other documents are pruned as well.
+	if c.PruneDefinitions || c.Include != "" || c.Exclude != "" || c.Per != "" {
+		s.unusedTypes = pruneDefinitions(&s, service, p.Api.Types, dataKeys)
+		// the unused types of the documents are reported once by the generator over the whole service.
+		if c.PruneDefinitions && c.Per == "" {
+			reportUnusedTypes(s.unusedTypes)
+		}
+	}
+	if err := sortSwagger(&s, c.Sort); err != nil {
+		return nil, err
+	}
 
 	return &s, nil
 }
 
-func renderServiceRoutes(service spec.Service, groups []spec.Group, paths swaggerPathsObject, requestResponseRefs refMap, pack, dataKey string) {
+func renderServiceRoutes(s *swaggerObject, service spec.Service, groups []spec.Group,
+	requestResponseRefs refMap, formLocations map[string]string, c *Config, dataKeys map[string]string,
+) error {
+	catalog := newParameterCatalog(s.Parameters, groups, c.ShareEmbedParameters)
+	operationIDs := make(map[string]struct{})
 	for _, group := range groups {
 		for _, route := range group.Routes {
 			var (
-				pathParamMap             = make(map[string]swaggerParameterObject)
+				pathParams               swaggerParametersObject
 				method                   = strings.ToUpper(route.Method)
 				parameters               swaggerParametersObject
 				hasBody                  bool
 				containForm, containJson bool
+				containFile              bool
 			)
 
 			path := group.GetAnnotation("prefix") + route.Path
@@ -195,53 +362,29 @@ func renderServiceRoutes(service spec.Service, groups []spec.Group, paths swagge
 							spo.Description = strings.Trim(prop, "\"")
 						}
 
-						pathParamMap[spo.Name] = spo
+						pathParams = append(pathParams, spo)
 					}
 				}
 			}
 
-			// parse "file_*" or "file_array_*" key from the @doc
-			// "*" means the file field name, it's like this below:
-			// 	@doc (
-			//		file_upload: "false, 上传文件"
-			//      file_array_upload: "false, 上传文件数组"
-			//	)
-			// its properties are separated by commas
-			// first one represents the file is it required
-			// second one represents the file description
-
 			var keys []string
 			for key := range route.AtDoc.Properties {
 				keys = append(keys, key)
 			}
 			sort.Strings(keys)
 			for _, k := range keys {
-				v := route.AtDoc.Properties[k]
-				if strings.HasPrefix(k, "file_") {
-					name := strings.TrimPrefix(k, "file_")
-					if strings.HasPrefix(k, "file_array_") {
-						name = strings.TrimPrefix(k, "file_array_") + "[]"
-					}
-					spo := swaggerParameterObject{
-						Name: name,
-						In:   "formData",
-						Type: "file",
-					}
-					if properties := strings.Split(strings.Trim(v, `"`), ","); len(properties) > 0 {
-						isRequired, _ := strconv.ParseBool(strings.TrimSpace(properties[0]))
-						spo.Required = isRequired
-						if len(properties) > 1 {
-							spo.Description = strings.TrimSpace(properties[1])
-						}
-					}
+				if spo, ok := parseFileParameter(k, route.AtDoc.Properties[k], c.PlainFileArrayName); ok {
 					parameters = append(parameters, spo)
 					containForm = true
+					containFile = true
+				} else if spo, ok := parseCookieParameter(k, route.AtDoc.Properties[k]); ok {
+					parameters = append(parameters, spo)
 				}
 			}
 
 			if defineStruct, ok := route.RequestType.(spec.DefineStruct); ok {
 				for _, member := range defineStruct.Members {
-					f, j := renderMember(pathParamMap, &parameters, member, method)
+					f, j := renderMember(&parameters, member, method)
 					if f {
 						containForm = true
 					}
@@ -249,12 +392,6 @@ func renderServiceRoutes(service spec.Service, groups []spec.Group, paths swagge
 						containJson = true
 					}
 				}
-
-				if len(pathParamMap) > 0 {
-					for _, p := range pathParamMap {
-						parameters = append(parameters, p)
-					}
-				}
 				if hasBody && containJson {
 					reqRef := "#/definitions/" + route.RequestType.Name()
 
@@ -272,10 +409,7 @@ func renderServiceRoutes(service spec.Service, groups []spec.Group, paths swagge
 							Schema:   &schema,
 						}
 
-						doc := strings.Join(route.RequestType.Documents(), ",")
-						doc = strings.TrimSpace(strings.ReplaceAll(doc, "//", ""))
-
-						if doc != "" {
+						if doc := docText(withoutExtensionDocs(route.RequestType.Documents())); doc != "" {
 							parameter.Description = doc
 						}
 
@@ -284,7 +418,22 @@ func renderServiceRoutes(service spec.Service, groups []spec.Group, paths swagge
 				}
 			}
 
-			pathItemObject, ok := paths[path]
+			parameters = mergePathParameters(route, pathParams, parameters)
+			parameters = mergeCookieParameters(parameters)
+
+			// go-zero binds the repeated keys to the slice, so "multi" is the default collection format,
+			// but it's only valid for the query and formData parameters.
+			collectionFormat := routeCollectionFormat(route, c.CollectionFormat)
+			for i := range parameters {
+				if parameters[i].Type == "array" {
+					parameters[i].CollectionFormat = collectionFormat
+					if in := parameters[i].In; in != "query" && in != "formData" && collectionFormat == collectionFormatMulti {
+						parameters[i].CollectionFormat = collectionFormatCsv
+					}
+				}
+			}
+
+			pathItemObject, ok := s.Paths[path]
 			if !ok {
 				pathItemObject = swaggerPathItemObject{}
 			}
@@ -299,156 +448,1540 @@ func renderServiceRoutes(service spec.Service, groups []spec.Group, paths swagge
 					refTypeName = strings.TrimPrefix(refTypeName, "*") // remove array item pointer
 
 					respSchema.Type = "array"
-					respSchema.Items = &swaggerItemsObject{Ref: "#/definitions/" + refTypeName}
+					respSchema.Items = &swaggerItemsObject{schemaCore: schemaCore{Ref: "#/definitions/" + refTypeName}}
 				} else {
 					respSchema.Ref = "#/definitions/" + route.ResponseType.Name()
 				}
 			}
-			tags := service.Name
-			if value := group.GetAnnotation("group"); len(value) > 0 {
-				namingFormat, err := format.FileNamingFormat(config.DefaultFormat, tags)
-				if err != nil {
-					return
+			tags := routeTags(service, group, route, c.TagNaming)
+
+			schema := swaggerSchemaObject{
+				schemaCore: respSchema,
+			}
+			if pack := routePack(group, route, c.Pack); pack != "" {
+				schema = swaggerSchemaObject{
+					AllOf: []swaggerSchemaObject{
+						{schemaCore: schemaCore{Ref: "#/definitions/" + strings.TrimPrefix(pack, "/")}},
+						{schemaCore: schemaCore{Type: "object"}, Properties: &swaggerSchemaObjectProperties{{Key: dataKeys[pack], Value: swaggerSchemaObject{schemaCore: respSchema}}}},
+					},
+				}
+			}
+			// streaming responses are written directly, so they are never packed.
+			mediaType := streamMediaType(group, route)
+			if mediaType == mimeEventStream {
+				desc = "A successful response.(streaming responses)"
+				schema = renderStreamDefinition(s.StreamDefinitions, route, respSchema)
+			} else if mediaType != "" {
+				desc = "A successful response.(streaming responses)"
+				schema = swaggerSchemaObject{schemaCore: schemaCore{Type: "file"}}
+			}
+
+			operationObject := &swaggerOperationObject{
+				Tags:       tags,
+				Parameters: parameters,
+				Responses: swaggerResponsesObject{
+					"200": swaggerResponseObject{
+						Description: desc,
+						Schema:      schema,
+					},
+				},
+			}
+			if mediaType != "" {
+				operationObject.Produces = []string{mediaType}
+			}
+
+			if hasBody {
+				consumes := routeConsumes(group, route, c.Consumes)
+				formIn := routeFormIn(group, route, c.FormIn)
+				if formIn == "" {
+					formIn = defaultFormIn(consumes, method, containForm, containJson)
+				}
+
+				if err := checkRequestLocations(consumes, formIn, containJson, containFile); err != nil {
+					return fmt.Errorf("%w, route: %s %s", err, route.Method, route.Path)
+				}
+
+				if formIn == formInBody {
+					if len(consumes) == 0 {
+						consumes = []string{mimeMultipart, mimeForm}
+						if containFile {
+							// files can only be uploaded by multipart/form-data
+							consumes = []string{mimeMultipart}
+						}
+					}
+
+					params := make(swaggerParametersObject, 0, len(operationObject.Parameters))
+					for _, param := range operationObject.Parameters {
+						if param.In == "query" {
+							param.In = "formData"
+						}
+						params = append(params, param)
+					}
+					operationObject.Parameters = params
+				}
+				operationObject.Consumes = consumes
+
+				if route.RequestType != nil && len(route.RequestType.Name()) > 0 {
+					name := route.RequestType.Name()
+					if in, ok := formLocations[name]; ok && in != formIn {
+						warnf("form members of %s are in both query and body, route: %s %s", name, route.Method, route.Path)
+					} else {
+						formLocations[name] = formIn
+					}
+				}
+			}
+
+			operationObject.Parameters = refSharedParameters(s.Parameters, catalog, operationObject.Parameters)
+
+			for _, v := range route.Doc {
+				markerIndex := strings.Index(v, atRespDoc)
+				if markerIndex >= 0 {
+					l := strings.Index(v, "(")
+					r := strings.Index(v, ")")
+					code := strings.TrimSpace(v[markerIndex+len(atRespDoc) : l])
+					var comment string
+					commentIndex := strings.Index(v, "//")
+					if commentIndex > 0 {
+						comment = strings.TrimSpace(strings.Trim(v[commentIndex+2:], "*/"))
+					}
+					content := strings.TrimSpace(v[l+1 : r])
+					if strings.Index(v, ":") > 0 {
+						lines := strings.Split(content, "\n")
+						kv := make(map[string]string, len(lines))
+						for _, line := range lines {
+							sep := strings.Index(line, ":")
+							key := strings.TrimSpace(line[:sep])
+							value := strings.TrimSpace(line[sep+1:])
+							kv[key] = value
+						}
+						kvByte, err := json.Marshal(kv)
+						if err != nil {
+							continue
+						}
+						operationObject.Responses[code] = swaggerResponseObject{
+							Description: comment,
+							Schema: swaggerSchemaObject{
+								schemaCore: schemaCore{
+									Example: string(kvByte),
+								},
+							},
+						}
+					} else if len(content) > 0 {
+						operationObject.Responses[code] = swaggerResponseObject{
+							Description: comment,
+							Schema: swaggerSchemaObject{
+								schemaCore: schemaCore{
+									Ref: "#/definitions/" + content,
+								},
+							},
+						}
+					}
+				}
+			}
+
+			// set OperationID
+			operationObject.OperationID = uniqueOperationID(operationIDs, routeOperationID(service, group, route, c.OperationID), route)
+
+			for _, param := range operationObject.Parameters {
+				if param.Schema != nil && param.Schema.Ref != "" {
+					requestResponseRefs[param.Schema.Ref] = struct{}{}
+				}
+			}
+			operationObject.Summary, operationObject.Description = routeDescription(route)
+
+			if deprecated, reason, sunset := routeDeprecation(route); deprecated {
+				operationObject.Deprecated = true
+				operationObject.Description = appendDeprecation(operationObject.Description, reason)
+				operationObject.Sunset = sunset
+			}
+
+			operationObject.Extensions = annotationExtensions(route.AtDoc.Properties)
+			operationObject.exampleSources = routeExampleSources(route.AtDoc.Properties)
+			operationObject.group = group.GetAnnotation(annotationKeyGroup)
+			if operationObject.group == "" {
+				operationObject.group = service.Name
+			}
+
+			if group.GetAnnotation("jwt") != "" ||
+				strings.Contains(strings.ToLower(group.GetAnnotation("middleware")), "jwt") {
+				operationObject.Security = &[]swaggerSecurityRequirementObject{{"apiKey": []string{}}}
+			}
+			if name := group.GetAnnotation(annotationKeyCookieAuth); name != "" {
+				security := swaggerSecurityRequirementObject{cookieSecurityPrefix + name: []string{}}
+				if operationObject.Security == nil {
+					operationObject.Security = &[]swaggerSecurityRequirementObject{}
 				}
+				*operationObject.Security = append(*operationObject.Security, security)
+			}
 
-				tags = filepath.Join(namingFormat, value)
+			switch method {
+			case http.MethodGet:
+				pathItemObject.Get = operationObject
+			case http.MethodPost:
+				pathItemObject.Post = operationObject
+			case http.MethodDelete:
+				pathItemObject.Delete = operationObject
+			case http.MethodPut:
+				pathItemObject.Put = operationObject
+			case http.MethodPatch:
+				pathItemObject.Patch = operationObject
 			}
 
-			if value := group.GetAnnotation("swtags"); len(value) > 0 {
-				namingFormat, err := format.FileNamingFormat(config.DefaultFormat, tags)
-				if err != nil {
-					return
+			for key, value := range annotationExtensions(group.Annotation.Properties) {
+				if pathItemObject.Extensions == nil {
+					pathItemObject.Extensions = make(swaggerExtensions)
+				}
+				if v, ok := pathItemObject.Extensions[key]; ok && !bytes.Equal(v, value) {
+					warnf("extension %s of path %s is declared with different values by groups, the first one %s is used", key, path, v)
+					continue
 				}
+				pathItemObject.Extensions[key] = value
+			}
+
+			s.Paths[path] = pathItemObject
+		}
+	}
+
+	return nil
+}
+
+// parseCookieParameter parses the "cookie_*" key from the @doc,
+// "*" means the cookie name, it's like this below:
+//
+//	@doc (
+//		cookie_session_id: "true, 会话id"
+//	)
+//
+// its properties are separated by commas,
+// first one represents the cookie is it required,
+// second one represents the cookie description.
+func parseCookieParameter(key, value string) (swaggerParameterObject, bool) {
+	if !strings.HasPrefix(key, atDocCookiePrefix) {
+		return swaggerParameterObject{}, false
+	}
+
+	spo := swaggerParameterObject{
+		Name: strings.TrimPrefix(key, atDocCookiePrefix),
+		In:   "cookie",
+		Type: "string",
+	}
+	properties := strings.SplitN(strings.Trim(value, `"`), ",", 2)
+	spo.Required, _ = strconv.ParseBool(strings.TrimSpace(properties[0]))
+	if len(properties) > 1 {
+		spo.Description = strings.TrimSpace(properties[1])
+	}
+
+	return spo, true
+}
+
+// mergeCookieParameters merges the cookie parameters into the Cookie header parameter,
+// because swagger 2.0 does not support the cookie parameter,
+// the cookies are listed in the description of the Cookie header.
+func mergeCookieParameters(parameters swaggerParametersObject) swaggerParametersObject {
+	var (
+		merged  = make(swaggerParametersObject, 0, len(parameters))
+		cookies []string
+		header  = swaggerParameterObject{Name: "Cookie", In: "header", Type: "string"}
+	)
+	for _, p := range parameters {
+		if p.In != "cookie" {
+			merged = append(merged, p)
+			continue
+		}
+
+		cookie := "- `" + p.Name + "`"
+		if p.Required {
+			cookie += " (required)"
+			header.Required = true
+		}
+		if p.Description != "" {
+			cookie += ": " + p.Description
+		}
+		cookies = append(cookies, cookie)
+	}
+	if len(cookies) == 0 {
+		return parameters
+	}
+
+	header.Description = "cookies:\n" + strings.Join(cookies, "\n")
+	return append(merged, header)
+}
+
+// parseFileParameter parses the "file_*" or "file_array_*" key from the @doc,
+// "*" means the file field name, it's like this below:
+//
+//	@doc (
+//		file_upload: "false, 上传文件"
+//		file_array_upload: "false, 上传文件数组"
+//		file_avatar: "true, 头像, mime=image/png|image/jpeg, max_size=2MB"
+//		file_array_photos: "true, 照片, mime=image/*, max_size=10MB, min_count=1, max_count=9"
+//	)
+//
+// its properties are separated by commas,
+// first one represents the file is it required,
+// second one represents the file description,
+// the rest are the options in key=value format:
+// mime is the allowed mime types separated by "|",
+// max_size is the max size of each file, which supports B, KB, MB and GB units,
+// min_count and max_count are the count range of the file array.
+//
+// swagger 2.0 does not allow file in array, so the file array is still a file parameter,
+// its multiplicity is described by the x-multiple, x-minCount and x-maxCount extensions.
+// the name of the file array has the "[]" suffix unless plainArrayName is true.
+func parseFileParameter(key, value string, plainArrayName bool) (swaggerParameterObject, bool) {
+	if !strings.HasPrefix(key, atDocFilePrefix) {
+		return swaggerParameterObject{}, false
+	}
+
+	spo := swaggerParameterObject{
+		Name: strings.TrimPrefix(key, atDocFilePrefix),
+		In:   "formData",
+		Type: "file",
+	}
+	ext := &swaggerFileExtensions{}
+	if strings.HasPrefix(key, atDocFileArrayPrefix) {
+		spo.Name = strings.TrimPrefix(key, atDocFileArrayPrefix)
+		if !plainArrayName {
+			spo.Name += "[]"
+		}
+		ext.Multiple = true
+	}
 
-				tags = filepath.Join(namingFormat, value)
+	var descs, notes []string
+	for i, property := range strings.Split(strings.Trim(value, `"`), ",") {
+		property = strings.TrimSpace(property)
+		if i == 0 {
+			spo.Required, _ = strconv.ParseBool(property)
+			continue
+		}
+
+		kv := strings.SplitN(property, equalToken, 2)
+		if len(kv) != 2 {
+			descs = append(descs, property)
+			continue
+		}
+		switch k, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]); k {
+		case fileOptionMime:
+			ext.MimeTypes = strings.Split(v, optionSeparator)
+			notes = append(notes, "allowed mime types: "+strings.Join(ext.MimeTypes, ", "))
+		case fileOptionMaxSize:
+			if size, ok := parseFileSize(v); ok {
+				ext.MaxSize = size
+				notes = append(notes, "max size: "+v)
+			}
+		case fileOptionMinCount:
+			ext.MinCount, _ = strconv.ParseUint(v, 10, 64)
+		case fileOptionMaxCount:
+			ext.MaxCount, _ = strconv.ParseUint(v, 10, 64)
+		default:
+			descs = append(descs, property)
+		}
+	}
+	if ext.MinCount > 0 {
+		spo.Required = true
+	}
+	if ext.MinCount > 0 || ext.MaxCount > 0 {
+		count := strconv.FormatUint(ext.MinCount, 10) + "~"
+		if ext.MaxCount > 0 {
+			count += strconv.FormatUint(ext.MaxCount, 10)
+		}
+		notes = append(notes, "file count: "+count)
+	}
+	if ext.Multiple {
+		notes = append([]string{"multiple files can be uploaded"}, notes...)
+	}
+
+	spo.Description = strings.Join(descs, ", ")
+	if len(notes) > 0 {
+		if spo.Description != "" {
+			spo.Description += "\n\n"
+		}
+		spo.Description += strings.Join(notes, "\n")
+	}
+	if ext.Multiple || len(ext.MimeTypes) > 0 || ext.MaxSize > 0 {
+		spo.swaggerFileExtensions = ext
+	}
+
+	return spo, true
+}
+
+// parseFileSize parses the file size like 512B, 100KB, 2MB or 1GB to bytes.
+func parseFileSize(size string) (int64, bool) {
+	units := []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+	size = strings.ToUpper(strings.TrimSpace(size))
+	for _, unit := range units {
+		if strings.HasSuffix(size, unit.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(size, unit.suffix)), 64)
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			return int64(n * float64(unit.bytes)), true
+		}
+	}
+
+	n, err := strconv.ParseInt(size, 10, 64)
+	return n, err == nil && n >= 0
+}
+
+// routeCollectionFormat returns the collection format of the array parameters of the route.
+// the default one can be specified by the config, and the "collection_format" key of the @doc
+// can override it per route, it's like this below:
+//
+//	@doc (
+//		collection_format: "csv"
+//	)
+func routeCollectionFormat(route spec.Route, defaultFormat string) string {
+	if v := strings.TrimSpace(strings.Trim(route.AtDoc.Properties[atDocKeyCollectionFormat], "\"")); v != "" {
+		return v
+	}
+	if defaultFormat != "" {
+		return defaultFormat
+	}
+
+	return collectionFormatMulti
+}
+
+// annotationExtensions returns the vendor extensions of the @server annotations or the @doc keys,
+// goctl does not allow "-" in the keys, so the keys start with "x_" and "_" is replaced with "-",
+// and the json values use single quotes for strings since the escaped quotes are not allowed, it's like this below:
+//
+//	@doc (
+//		x_rate_limit: "{'rate': 10, 'burst': 20}" // output as "x-rate-limit": {"rate": 10, "burst": 20}
+//		x_internal: "true"                        // output as "x-internal": true
+//	)
+func annotationExtensions(properties map[string]string) swaggerExtensions {
+	var extensions swaggerExtensions
+	for key, value := range properties {
+		if !strings.HasPrefix(key, extensionKeyPrefix) {
+			continue
+		}
+		if extensions == nil {
+			extensions = make(swaggerExtensions)
+		}
+		extensions[strings.ReplaceAll(key, "_", "-")] = extensionValue(unquote(value))
+	}
+
+	return extensions
+}
+
+// memberExtensions returns the vendor extensions of the member tag options,
+// e.g. `json:"name,x-order=1,x-nullable"`, the option without value is true.
+func memberExtensions(member spec.Member) swaggerExtensions {
+	var extensions swaggerExtensions
+	for _, tag := range member.Tags() {
+		for _, option := range tag.Options {
+			if !strings.HasPrefix(option, extensionPrefix) {
+				continue
 			}
+			if extensions == nil {
+				extensions = make(swaggerExtensions)
+			}
+			key, value, ok := strings.Cut(option, "=")
+			if !ok {
+				value = "true"
+			}
+			extensions[key] = extensionValue(value)
+		}
+	}
+
+	return extensions
+}
+
+// docExtensions returns the vendor extensions of the type doc lines like "// x-internal: true".
+func docExtensions(docs []string) swaggerExtensions {
+	var extensions swaggerExtensions
+	for _, doc := range docs {
+		doc = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(doc), "/"))
+		if !strings.HasPrefix(doc, extensionPrefix) {
+			continue
+		}
+		key, value, ok := strings.Cut(doc, ":")
+		if !ok {
+			continue
+		}
+		if extensions == nil {
+			extensions = make(swaggerExtensions)
+		}
+		extensions[strings.TrimSpace(key)] = extensionValue(strings.TrimSpace(value))
+	}
+
+	return extensions
+}
+
+// withoutExtensionDocs returns the type doc lines except the vendor extension ones.
+func withoutExtensionDocs(docs []string) []string {
+	var ret []string
+	for _, doc := range docs {
+		if !strings.HasPrefix(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(doc), "/")), extensionPrefix) {
+			ret = append(ret, doc)
+		}
+	}
+
+	return ret
+}
+
+// extensionValue returns the json literal value as it is, and the json with single quotes is converted,
+// otherwise returns the value as a json string.
+func extensionValue(value string) json.RawMessage {
+	if json.Valid([]byte(value)) {
+		return json.RawMessage(value)
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		if v, ok := singleQuotedJSON(value); ok {
+			return v
+		}
+	}
+
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// singleQuotedJSON converts the json whose strings are quoted by single quotes to the standard json,
+// e.g. {'name': 'it\'s', 'note': "it's"} to {"name": "it's", "note": "it's"},
+// the apostrophes in the double-quoted strings are kept, and \' escapes the single quote in the single-quoted strings.
+func singleQuotedJSON(value string) (json.RawMessage, bool) {
+	var (
+		buf   bytes.Buffer
+		quote rune
+	)
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == 0:
+			if r == '\'' {
+				quote = r
+				r = '"'
+			} else if r == '"' {
+				quote = r
+			}
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, false
+			}
+			i++
+			if quote == '\'' && runes[i] == '\'' {
+				buf.WriteRune('\'')
+				continue
+			}
+			buf.WriteRune(r)
+			r = runes[i]
+		case r == quote:
+			quote = 0
+			r = '"'
+		case r == '"':
+			// the double quote in the single-quoted string
+			buf.WriteRune('\\')
+		}
+		buf.WriteRune(r)
+	}
+	if quote != 0 || !json.Valid(buf.Bytes()) {
+		return nil, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// routeDescription returns the summary and the description of the route in markdown,
+// the summary is the summary of the @doc, or the first paragraph of the handler comments by default,
+// and the description is the rest of the handler comments followed by the description of the @doc,
+// which supports the escaped line breaks, it's like this below:
+//
+//	// List orders
+//	//
+//	// Orders are sorted by "created_at".
+//	@doc (
+//		description: "line one\n\n- item one\n- item two"
+//	)
+func routeDescription(route spec.Route) (summary, description string) {
+	summary = strings.TrimSpace(unquote(route.AtDoc.Text) + unquote(route.AtDoc.Properties[atDocKeySummary]))
+	docs := docText(route.HandlerDoc)
+	if docs == "" {
+		docs = docText(route.Docs)
+	}
+	if summary == "" {
+		summary, docs, _ = strings.Cut(docs, "\n\n")
+		summary = strings.Join(strings.Fields(summary), " ")
+		docs = strings.TrimSpace(docs)
+	}
+
+	return summary, joinParagraphs(docs, strings.TrimSpace(unquote(route.AtDoc.Properties[atDocKeyDescription])))
+}
+
+// memberDescription returns the description of the member in markdown,
+// which is the doc comments above the member followed by the comment behind it.
+func memberDescription(member spec.Member) string {
+	return joinParagraphs(docText(member.Docs), docText([]string{member.Comment}))
+}
+
+// docText returns the markdown text of the comment lines, the line breaks and blank lines are kept,
+// and the escaped line breaks are converted.
+func docText(docs []string) string {
+	lines := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		doc = strings.TrimSpace(doc)
+		switch {
+		case strings.HasPrefix(doc, "//"):
+			doc = strings.TrimPrefix(doc, "//")
+		case strings.HasPrefix(doc, "/*"):
+			doc = strings.TrimSuffix(strings.TrimPrefix(doc, "/*"), "*/")
+		}
+		lines = append(lines, strings.TrimPrefix(strings.TrimRight(doc, " \t"), " "))
+	}
+
+	return strings.TrimSpace(strings.ReplaceAll(strings.Join(lines, "\n"), "\\n", "\n"))
+}
+
+// joinParagraphs joins the non-empty paragraphs with blank lines.
+func joinParagraphs(paragraphs ...string) string {
+	ret := make([]string, 0, len(paragraphs))
+	for _, p := range paragraphs {
+		if p != "" {
+			ret = append(ret, p)
+		}
+	}
+
+	return strings.Join(ret, "\n\n")
+}
+
+// routeDeprecation returns whether the route is deprecated, the reason and the sunset date,
+// the route is deprecated by the "deprecated" key of the @doc, it's like this below:
+//
+//	@doc (
+//		deprecated: "use /v2/orders; removal 2027-01" // or "true" without reason
+//		sunset: "2027-01-31"                          // optional, the date in the reason by default
+//	)
+func routeDeprecation(route spec.Route) (deprecated bool, reason, sunset string) {
+	v, ok := route.AtDoc.Properties[atDocKeyDeprecated]
+	if !ok {
+		return false, "", ""
+	}
+
+	reason = strings.TrimSpace(strings.Trim(v, `"`))
+	if b, err := strconv.ParseBool(reason); err == nil {
+		if !b {
+			return false, "", ""
+		}
+		reason = ""
+	}
+
+	sunset = strings.TrimSpace(strings.Trim(route.AtDoc.Properties[atDocKeySunset], `"`))
+	if sunset == "" {
+		sunset = sunsetDateRegexp.FindString(reason)
+	}
+
+	return true, reason, sunset
+}
+
+// memberDeprecation returns whether the member is deprecated and the reason,
+// the member is deprecated by the "// Deprecated: reason" comment or the "deprecated" tag option.
+func memberDeprecation(member spec.Member) (deprecated bool, reason string) {
+	for _, comment := range append([]string{member.Comment}, member.Docs...) {
+		comment = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(comment), "/"))
+		if strings.HasPrefix(comment, deprecatedPrefix) {
+			return true, strings.TrimSpace(strings.TrimPrefix(comment, deprecatedPrefix))
+		}
+	}
+
+	for _, tag := range member.Tags() {
+		if contains(tag.Options, deprecatedOption) {
+			return true, ""
+		}
+	}
+
+	return false, ""
+}
+
+// appendDeprecation appends the deprecation reason to the description if it's not mentioned yet.
+func appendDeprecation(desc, reason string) string {
+	if strings.Contains(desc, deprecatedPrefix) {
+		return desc
+	}
+
+	text := "Deprecated"
+	if reason != "" {
+		text = deprecatedPrefix + " " + reason
+	}
+	if desc == "" {
+		return text
+	}
+
+	return desc + "\n\n" + text
+}
+
+// filterService returns the service with the routes which match any of the include selectors
+// and none of the exclude selectors, the selectors are separated by commas, it's like this below:
+//
+//	group:order        the group of the @server, supports the glob pattern
+//	prefix:/v1/admin   the path with the prefix of the @server is under it
+//	tag:admin          the route has the tag
+//	handler:admin*     the handler name matches the glob pattern
+//	internal           the route or its group is marked by internal or x_internal, e.g. @doc(internal: "true")
+func filterService(service spec.Service, include, exclude, tagNaming string) (spec.Service, error) {
+	includes, err := parseRouteSelectors(include)
+	if err != nil {
+		return service, fmt.Errorf("parse include err: %w", err)
+	}
+	excludes, err := parseRouteSelectors(exclude)
+	if err != nil {
+		return service, fmt.Errorf("parse exclude err: %w", err)
+	}
+	if len(includes) == 0 && len(excludes) == 0 {
+		return service, nil
+	}
+
+	groups := make([]spec.Group, 0, len(service.Groups))
+	for _, group := range service.Groups {
+		routes := make([]spec.Route, 0, len(group.Routes))
+		for _, route := range group.Routes {
+			if len(includes) > 0 && !matchRouteSelectors(includes, service, group, route, tagNaming) {
+				continue
+			}
+			if matchRouteSelectors(excludes, service, group, route, tagNaming) {
+				continue
+			}
+			routes = append(routes, route)
+		}
+		if len(routes) > 0 {
+			group.Routes = routes
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		warnf("no routes are left after filtering by include %q and exclude %q", include, exclude)
+	}
+	service.Groups = groups
+
+	return service, nil
+}
+
+type document struct {
+	name    string
+	service spec.Service
+}
+
+// partitionService partitions the routes of the service into the documents by the group, the prefix
+// or the version segment of the path like v1, the routes which have no group, prefix or version
+// are in the default document, and the documents are sorted by name.
+func partitionService(service spec.Service, per string) ([]document, error) {
+	var keyOf func(group spec.Group, route spec.Route) string
+	switch per {
+	case perGroup:
+		keyOf = func(group spec.Group, _ spec.Route) string {
+			return group.GetAnnotation(annotationKeyGroup)
+		}
+	case perPrefix:
+		keyOf = func(group spec.Group, _ spec.Route) string {
+			return strings.Trim(group.GetAnnotation("prefix"), "/")
+		}
+	case perVersion:
+		keyOf = func(group spec.Group, route spec.Route) string {
+			for _, segment := range strings.Split(group.GetAnnotation("prefix")+"/"+route.Path, "/") {
+				if versionSegmentRegexp.MatchString(segment) {
+					return segment
+				}
+			}
+			return ""
+		}
+	default:
+		return nil, fmt.Errorf("unsupported per: %s, only support [group prefix version]", per)
+	}
+
+	index := make(map[string]int)
+	var docs []document
+	for _, group := range service.Groups {
+		routes := make(map[string][]spec.Route)
+		var names []string
+		for _, route := range group.Routes {
+			name := keyOf(group, route)
+			if name == "" {
+				name = defaultDocumentName
+			}
+			if _, ok := routes[name]; !ok {
+				names = append(names, name)
+			}
+			routes[name] = append(routes[name], route)
+		}
+		for _, name := range names {
+			i, ok := index[name]
+			if !ok {
+				i = len(docs)
+				index[name] = i
+				docs = append(docs, document{name: name, service: spec.Service{Name: service.Name}})
+			}
+			g := group
+			g.Routes = routes[name]
+			docs[i].service.Groups = append(docs[i].service.Groups, g)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].name < docs[j].name })
+
+	return docs, nil
+}
+
+// filenameTemplate returns the file name template of the documents based on the file name,
+// the name of the document is inserted before the extension, e.g. api-{name}.swagger.json for api.swagger.json.
+func filenameTemplate(filename string) string {
+	ext := filepath.Ext(filename)
+	if strings.HasSuffix(filename, swaggerExt) {
+		ext = swaggerExt
+	}
+
+	return strings.TrimSuffix(filename, ext) + "-{name}" + ext
+}
+
+// documentFilename returns the file name of the document by the template with {service} and {name},
+// {group}, {prefix} and {version} are the aliases of {name}.
+func documentFilename(tmpl, service, name string) string {
+	name = splitFileName(name)
+	return strings.NewReplacer("{service}", splitFileName(service), "{name}", name,
+		"{group}", name, "{prefix}", name, "{version}", name).Replace(tmpl)
+}
+
+type routeSelector struct {
+	kind    string
+	pattern string
+}
+
+// parseRouteSelectors parses the route selectors separated by commas.
+func parseRouteSelectors(selectors string) ([]routeSelector, error) {
+	var ret []routeSelector
+	for _, v := range strings.Split(selectors, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		kind, pattern, _ := strings.Cut(v, ":")
+		kind, pattern = strings.TrimSpace(kind), strings.TrimSpace(pattern)
+		switch kind {
+		case selectorInternal:
+		case selectorGroup, selectorPrefix, selectorTag, selectorHandler:
+			if pattern == "" {
+				return nil, fmt.Errorf("selector %s has no pattern", v)
+			}
+			if _, err := pathpkg.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("selector %s has invalid pattern: %w", v, err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported selector: %s, only support [group tag prefix handler internal]", v)
+		}
+		ret = append(ret, routeSelector{kind: kind, pattern: pattern})
+	}
+
+	return ret, nil
+}
+
+// matchRouteSelectors returns true if the route matches any of the selectors.
+func matchRouteSelectors(selectors []routeSelector, service spec.Service, group spec.Group, route spec.Route, tagNaming string) bool {
+	for _, selector := range selectors {
+		switch selector.kind {
+		case selectorGroup:
+			if ok, _ := pathpkg.Match(selector.pattern, group.GetAnnotation(annotationKeyGroup)); ok {
+				return true
+			}
+		case selectorPrefix:
+			path := group.GetAnnotation("prefix") + route.Path
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+			prefix := "/" + strings.Trim(selector.pattern, "/")
+			if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+				return true
+			}
+		case selectorTag:
+			for _, tag := range routeTags(service, group, route, tagNaming) {
+				if ok, _ := pathpkg.Match(selector.pattern, tag); ok {
+					return true
+				}
+			}
+		case selectorHandler:
+			if ok, _ := pathpkg.Match(selector.pattern, route.Handler); ok {
+				return true
+			}
+		case selectorInternal:
+			if isInternal(group.Annotation.Properties) || isInternal(route.AtDoc.Properties) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isInternal returns true if the internal or x_internal property is true.
+func isInternal(properties map[string]string) bool {
+	for _, key := range []string{selectorInternal, extensionKeyPrefix + selectorInternal} {
+		if v, err := strconv.ParseBool(strings.TrimSpace(unquote(properties[key]))); err == nil && v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// routeOperationID returns the operationId of the route by the strategy,
+// the "operationId" key of the @doc can override it per route, it's like this below:
+//
+//	@doc (
+//		operationId: "listOrders"
+//	)
+//
+// the strategy can be "handler", "group_handler", "method_path",
+// or a custom template with {group}, {handler}, {method} and {path}, the default one is "handler".
+// {group} is the group annotation of the @server, or the prefix if it's not annotated, or the service name.
+func routeOperationID(service spec.Service, group spec.Group, route spec.Route, strategy string) string {
+	if v := strings.TrimSpace(strings.Trim(route.AtDoc.Properties[atDocKeyOperationID], `"`)); v != "" {
+		return v
+	}
+
+	template := "{handler}"
+	switch strategy {
+	case "", operationIDHandler:
+	case operationIDGroupHandler:
+		template = "{group}_{handler}"
+	case operationIDMethodPath:
+		template = "{method}_{path}"
+	default:
+		template = strategy
+	}
+
+	pathReplacer := strings.NewReplacer(":", "", "{", "", "}", "", "-", "_", ".", "_")
+	path := pathReplacer.Replace(strings.Trim(group.GetAnnotation(spec.RoutePrefixKey)+"/"+route.Path, "/"))
+	groupName := group.GetAnnotation(annotationKeyGroup)
+	if groupName == "" {
+		groupName = pathReplacer.Replace(strings.Trim(group.GetAnnotation(spec.RoutePrefixKey), "/"))
+	}
+	if groupName == "" {
+		groupName = pathReplacer.Replace(service.Name)
+	}
+	id := strings.NewReplacer(
+		"{group}", groupName,
+		"{handler}", route.Handler,
+		"{method}", strings.ToLower(route.Method),
+		"{path}", path,
+	).Replace(template)
+	id = strings.ReplaceAll(id, "/", "_")
+	for strings.Contains(id, "__") {
+		id = strings.ReplaceAll(id, "__", "_")
+	}
+
+	return strings.Trim(id, "_")
+}
+
+// checkOperationID checks the operationId strategy is a named one or a template with the placeholders,
+// so a mistyped strategy is not used as the literal operationId of all the routes.
+func checkOperationID(strategy string) error {
+	switch strategy {
+	case "", operationIDHandler, operationIDGroupHandler, operationIDMethodPath:
+		return nil
+	}
+	for _, placeholder := range operationIDPlaceholders {
+		if strings.Contains(strategy, placeholder) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported operationId: %s, only support [%s %s %s] or the template with %v",
+		strategy, operationIDHandler, operationIDGroupHandler, operationIDMethodPath, operationIDPlaceholders)
+}
+
+// uniqueOperationID makes the operationId unique by appending the numeric suffix and warns about the collision.
+func uniqueOperationID(operationIDs map[string]struct{}, id string, route spec.Route) string {
+	unique := id
+	for i := 2; ; i++ {
+		if _, ok := operationIDs[unique]; !ok {
+			break
+		}
+		unique = id + "_" + strconv.Itoa(i)
+	}
+	if unique != id {
+		warnf("operationId %q is duplicated, %q is used instead, route: %s %s", id, unique, route.Method, route.Path)
+	}
+	operationIDs[unique] = struct{}{}
+
+	return unique
+}
+
+// groupTags returns the tags of the operations in the group,
+// they are the swtags separated by commas or the group annotation of the @server,
+// or the service name if neither is annotated.
+// by default, the tags are nested under the service name and the group like "service/group/swtag",
+// the plain tag naming uses them as they are.
+func groupTags(service spec.Service, group spec.Group, tagNaming string) []string {
+	if tagNaming == tagNamingPlain {
+		if value := group.GetAnnotation(annotationKeySwtags); len(value) > 0 {
+			return splitTags(nil, value)
+		}
+		if value := group.GetAnnotation(annotationKeyGroup); len(value) > 0 {
+			return []string{value}
+		}
+
+		return []string{service.Name}
+	}
+
+	tag := service.Name
+	if value := group.GetAnnotation(annotationKeyGroup); len(value) > 0 {
+		tag = nestedTag(tag, value)
+	}
+	if value := group.GetAnnotation(annotationKeySwtags); len(value) > 0 {
+		var tags []string
+		for _, name := range splitTags(nil, value) {
+			tags = append(tags, nestedTag(tag, name))
+		}
+		return tags
+	}
+
+	return []string{tag}
+}
+
+// nestedTag joins the tag under the parent tag, which is formatted in the default goctl file naming style,
+// they are always joined by slash, so the tags are the same on all platforms.
+func nestedTag(parent, tag string) string {
+	if formatted, err := format.FileNamingFormat(config.DefaultFormat, parent); err == nil {
+		parent = formatted
+	}
+
+	return pathpkg.Join(parent, tag)
+}
+
+// routeTags returns the tags of the operation, the "tags" key of the @doc adds more tags to the group tags,
+// they are separated by commas, it's like this below:
+//
+//	@doc (
+//		tags: "orders,admin"
+//	)
+func routeTags(service spec.Service, group spec.Group, route spec.Route, tagNaming string) []string {
+	return splitTags(groupTags(service, group, tagNaming), strings.Trim(route.AtDoc.Properties[atDocKeyTags], `"`))
+}
+
+// splitTags splits the tags separated by commas and appends the ones not in the given tags.
+func splitTags(tags []string, value string) []string {
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// renderInfo renders the info object and the external docs from the info block of the api file,
+// which are overridden by the non-empty fields of the config, the info block is like this below:
+//
+//	info (
+//		title:       "demo"
+//		desc:        "demo api"
+//		version:     "1.0"
+//		author:      "someone"
+//		email:       "someone@example.com"
+//		contact_url: "https://example.com/support"
+//		license:     "MIT"
+//		license_url: "https://opensource.org/licenses/MIT"
+//		terms:       "https://example.com/terms"
+//		docs_url:    "https://example.com/docs"
+//		docs_desc:   "more documents"
+//	)
+func renderInfo(s *swaggerObject, info spec.Info, c *Config) error {
+	property := func(key string) string {
+		return strings.TrimSpace(unquote(info.Properties[key]))
+	}
+
+	s.Info = swaggerInfoObject{
+		Title:          property(infoKeyTitle),
+		Version:        property(infoKeyVersion),
+		Description:    property(infoKeyDesc),
+		TermsOfService: property(infoKeyTerms),
+		Contact: &swaggerContactObject{
+			Name:  property(infoKeyAuthor),
+			URL:   property(infoKeyContactURL),
+			Email: property(infoKeyEmail),
+		},
+		License: &swaggerLicenseObject{
+			Name: property(infoKeyLicense),
+			URL:  property(infoKeyLicenseURL),
+		},
+	}
+	s.ExternalDocs = &swaggerExternalDocumentationObject{
+		Description: property(infoKeyDocsDesc),
+		URL:         property(infoKeyDocsURL),
+	}
+
+	if len(c.Info) > 0 {
+		var ci swaggerInfoObject
+		if err := json.Unmarshal(c.Info, &ci); err != nil {
+			return fmt.Errorf("parse info err: %w", err)
+		}
+		overwrite(&s.Info.Title, ci.Title)
+		overwrite(&s.Info.Version, ci.Version)
+		overwrite(&s.Info.Description, ci.Description)
+		overwrite(&s.Info.TermsOfService, ci.TermsOfService)
+		if ci.Contact != nil {
+			overwrite(&s.Info.Contact.Name, ci.Contact.Name)
+			overwrite(&s.Info.Contact.URL, ci.Contact.URL)
+			overwrite(&s.Info.Contact.Email, ci.Contact.Email)
+		}
+		if ci.License != nil {
+			overwrite(&s.Info.License.Name, ci.License.Name)
+			overwrite(&s.Info.License.URL, ci.License.URL)
+		}
+	}
+	if len(c.ExternalDocs) > 0 {
+		var docs swaggerExternalDocumentationObject
+		if err := json.Unmarshal(c.ExternalDocs, &docs); err != nil {
+			return fmt.Errorf("parse external docs err: %w", err)
+		}
+		overwrite(&s.ExternalDocs.Description, docs.Description)
+		overwrite(&s.ExternalDocs.URL, docs.URL)
+	}
+
+	if *s.Info.Contact == (swaggerContactObject{}) {
+		s.Info.Contact = nil
+	}
+	if *s.Info.License == (swaggerLicenseObject{}) {
+		s.Info.License = nil
+	} else if s.Info.License.Name == "" {
+		warnf("license url %q is declared without the license name, which is required", s.Info.License.URL)
+	}
+	if s.ExternalDocs.URL == "" {
+		if s.ExternalDocs.Description != "" {
+			warnf("external docs description %q is declared without the url, which is required", s.ExternalDocs.Description)
+		}
+		s.ExternalDocs = nil
+	}
+
+	return nil
+}
+
+// renderServers renders the x-servers from the config,
+// and renders host, basePath and schemes from the url of the selected server.
+func renderServers(s *swaggerObject, c *Config) error {
+	if len(c.Servers) == 0 {
+		if c.Server != "" {
+			return fmt.Errorf("server %q is selected without servers", c.Server)
+		}
+		return nil
+	}
+
+	var servers []struct {
+		Name string `json:"name"`
+		swaggerServerObject
+	}
+	if err := json.Unmarshal(c.Servers, &servers); err != nil {
+		return fmt.Errorf("parse servers err: %w", err)
+	}
+	if len(servers) == 0 {
+		return nil
+	}
+
+	selected := -1
+	for i, server := range servers {
+		if server.URL == "" {
+			return fmt.Errorf("server %q has no url", server.Name)
+		}
+		if c.Server == "" || server.Name == c.Server {
+			if selected < 0 {
+				selected = i
+			}
+		}
+		s.Servers = append(s.Servers, server.swaggerServerObject)
+	}
+	if selected < 0 {
+		return fmt.Errorf("server %q is not declared in servers", c.Server)
+	}
+
+	rawURL, err := serverURL(s.Servers[selected])
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse server url %q err: %w", rawURL, err)
+	}
+	if u.Scheme != "" {
+		if !contains(supportedSchemes, u.Scheme) {
+			return fmt.Errorf("unsupport server url scheme: [%s], only support [http, https, ws, wss]", u.Scheme)
+		}
+		s.Schemes = []string{u.Scheme}
+	}
+	s.Host = u.Host
+	if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+		s.BasePath = p
+	}
+
+	return nil
+}
+
+// serverURL returns the server url whose variables are replaced with their default values.
+func serverURL(server swaggerServerObject) (string, error) {
+	var err error
+	rawURL := serverVariableRegexp.ReplaceAllStringFunc(server.URL, func(v string) string {
+		name := strings.Trim(v, "{}")
+		variable, ok := server.Variables[name]
+		if !ok {
+			err = fmt.Errorf("variable %q of server url %q is not declared", name, server.URL)
+			return v
+		}
+		return variable.Default
+	})
+
+	return rawURL, err
+}
+
+// unquote returns the unquoted value if it's quoted, otherwise returns the value itself.
+// the value with the escapes which are not valid in go, such as \', is only stripped of the quotes.
+func unquote(v string) string {
+	if u, err := strconv.Unquote(v); err == nil {
+		return u
+	}
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// overwrite sets the value to dst if it's not empty.
+func overwrite(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// renderTags renders the tags declared in the config in their order, followed by the other used tags,
+// the summary annotation of the @server describes the first tag of the group if it's not declared with description.
+// the tag groups declared in the config are rendered as x-tagGroups,
+// and the tags not in any of them are collected into the "Others" group.
+// the unused tags declared in the config are omitted if the routes are filtered.
+func renderTags(s *swaggerObject, service spec.Service, c *Config) error {
+	if len(c.Tags) > 0 {
+		if err := json.Unmarshal(c.Tags, &s.Tags); err != nil {
+			return fmt.Errorf("parse tags err: %w", err)
+		}
+	}
+	if len(c.TagGroups) > 0 {
+		if err := json.Unmarshal(c.TagGroups, &s.TagGroups); err != nil {
+			return fmt.Errorf("parse tag groups err: %w", err)
+		}
+	}
+
+	index := make(map[string]int, len(s.Tags))
+	for i, tag := range s.Tags {
+		index[tag.Name] = i
+	}
+	used := make(map[string]struct{})
+	addTag := func(name, desc string) {
+		used[name] = struct{}{}
+		i, ok := index[name]
+		if !ok {
+			i = len(s.Tags)
+			index[name] = i
+			s.Tags = append(s.Tags, swaggerTagObject{Name: name})
+		}
+		if s.Tags[i].Description == "" {
+			s.Tags[i].Description = desc
+		}
+	}
+	for _, group := range service.Groups {
+		if len(group.Routes) == 0 {
+			continue
+		}
+		// summary describes the first tag of the group
+		desc := strings.TrimSpace(group.GetAnnotation(annotationKeySummary))
+		for _, name := range groupTags(service, group, c.TagNaming) {
+			addTag(name, desc)
+			desc = ""
+		}
+		for _, route := range group.Routes {
+			for _, name := range routeTags(service, group, route, c.TagNaming) {
+				addTag(name, "")
+			}
+		}
+	}
+
+	// the filtered documents only have the declared tags which are used by the remaining operations.
+	if c.Include != "" || c.Exclude != "" || c.Per != "" {
+		tags := s.Tags[:0]
+		for _, tag := range s.Tags {
+			if _, ok := used[tag.Name]; ok {
+				tags = append(tags, tag)
+			}
+		}
+		s.Tags = tags
+
+		tagGroups := s.TagGroups[:0]
+		for _, tagGroup := range s.TagGroups {
+			names := make([]string, 0, len(tagGroup.Tags))
+			for _, name := range tagGroup.Tags {
+				if _, ok := used[name]; ok {
+					names = append(names, name)
+				}
+			}
+			if len(names) > 0 {
+				tagGroup.Tags = names
+				tagGroups = append(tagGroups, tagGroup)
+			}
+		}
+		s.TagGroups = tagGroups
+	}
+
+	if len(s.TagGroups) > 0 {
+		grouped := make(map[string]struct{})
+		for _, tagGroup := range s.TagGroups {
+			for _, tag := range tagGroup.Tags {
+				grouped[tag] = struct{}{}
+			}
+		}
+		others := swaggerTagGroupObject{Name: "Others"}
+		for _, tag := range s.Tags {
+			if _, ok := grouped[tag.Name]; !ok {
+				others.Tags = append(others.Tags, tag.Name)
+			}
+		}
+		if len(others.Tags) > 0 {
+			s.TagGroups = append(s.TagGroups, others)
+		}
+	}
+
+	return nil
+}
+
+// routeFormIn returns where the form members of the request are located on the route with body,
+// "query" means they are query parameters, "body" means they are form fields in the body,
+// empty means it is not specified and depends on the request.
+// the default one can be specified by the config, and selected per group by @server(form_in: xxx),
+// the "form_in" key of the @doc can override it per route, it's like this below:
+//
+//	@doc (
+//		form_in: "query"
+//	)
+func routeFormIn(group spec.Group, route spec.Route, defaultFormIn string) string {
+	formIn := defaultFormIn
+	if v := strings.TrimSpace(group.GetAnnotation(annotationKeyFormIn)); v != "" {
+		formIn = v
+	}
+	if v := strings.TrimSpace(strings.Trim(route.AtDoc.Properties[atDocKeyFormIn], "\"")); v != "" {
+		formIn = v
+	}
+
+	return formIn
+}
+
+// defaultFormIn returns where the form members are located when it's not specified.
+// with the declared media types, form members are in the body only if json is not declared.
+// otherwise, form members are in the body only if there are no json members and the method is not DELETE,
+// because query and form fields share the "form" tag and can not be distinguished.
+func defaultFormIn(consumes []string, method string, containForm, containJson bool) string {
+	if len(consumes) > 0 {
+		if contains(consumes, mimeJson) {
+			return formInQuery
+		}
+		return formInBody
+	}
+	if containForm && !containJson && method != http.MethodDelete {
+		return formInBody
+	}
+
+	return formInQuery
+}
+
+// checkRequestLocations checks that the json and form members of the request can be sent together,
+// swagger 2.0 does not allow body and formData parameters at the same time,
+// and go-zero can not bind the json body and the form body of one request either.
+func checkRequestLocations(consumes []string, formIn string, containJson, containFile bool) error {
+	switch formIn {
+	case formInBody:
+		if containJson {
+			return errors.New("json members can not be sent with the form members in the body, " +
+				"declare form_in: query or move the json members out of the request")
+		}
+		if contains(consumes, mimeJson) {
+			return fmt.Errorf("form members in the body can not be sent as %s", mimeJson)
+		}
+	default:
+		if containFile {
+			return errors.New("files can only be uploaded with the form members in the body, declare form_in: body")
+		}
+		if containJson && len(consumes) > 0 && !contains(consumes, mimeJson) {
+			return fmt.Errorf("json members can not be sent as %s", strings.Join(consumes, ", "))
+		}
+	}
+
+	return nil
+}
+
+// routeConsumes returns the declared request media types of the route.
+// the default ones can be declared by the config, and selected per group by @server(consumes: xxx),
+// the "consumes" key of the @doc can override them per route, it's like this below:
+//
+//	@doc (
+//		consumes: "multipart/form-data, application/x-www-form-urlencoded"
+//	)
+//
+// media types are separated by commas, "json", "form" and "multipart" are the aliases of
+// "application/json", "application/x-www-form-urlencoded" and "multipart/form-data".
+func routeConsumes(group spec.Group, route spec.Route, defaultConsumes string) []string {
+	consumes := defaultConsumes
+	if v := strings.TrimSpace(group.GetAnnotation(annotationKeyConsumes)); v != "" {
+		consumes = v
+	}
+	if v := strings.TrimSpace(strings.Trim(route.AtDoc.Properties[atDocKeyConsumes], "\"")); v != "" {
+		consumes = v
+	}
+
+	var mediaTypes []string
+	for _, mediaType := range strings.Split(consumes, ",") {
+		mediaType = strings.TrimSpace(mediaType)
+		if alias, ok := mediaTypeAliases[mediaType]; ok {
+			mediaType = alias
+		}
+		if mediaType != "" && !contains(mediaTypes, mediaType) {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+
+	return mediaTypes
+}
+
+// routePack returns the outer packaging response name of the route, empty means no packaging.
+// the default one can be selected per group by @server(pack: xxx),
+// and the "pack" key of the @doc can override it per route, it's like this below:
+//
+//	@doc (
+//		pack: "LegacyResponse" // use the outer packaging response named LegacyResponse
+//		pack: "none"           // no packaging
+//	)
+func routePack(group spec.Group, route spec.Route, defaultPack string) string {
+	pack := defaultPack
+	if v := strings.TrimSpace(group.GetAnnotation(annotationKeyPack)); v != "" {
+		pack = v
+	}
+	if v := strings.TrimSpace(strings.Trim(route.AtDoc.Properties[atDocKeyPack], "\"")); v != "" {
+		pack = v
+	}
+	if pack == packNone {
+		return ""
+	}
 
-			schema := swaggerSchemaObject{
-				schemaCore: respSchema,
-			}
-			if pack != "" {
-				schema = swaggerSchemaObject{
-					AllOf: []swaggerSchemaObject{
-						{schemaCore: schemaCore{Ref: "#/definitions/" + strings.TrimPrefix(pack, "/")}},
-						{schemaCore: schemaCore{Type: "object"}, Properties: &swaggerSchemaObjectProperties{{Key: dataKey, Value: respSchema}}},
-					},
-				}
-			}
-			operationObject := &swaggerOperationObject{
-				Tags:       []string{tags},
-				Parameters: parameters,
-				Responses: swaggerResponsesObject{
-					"200": swaggerResponseObject{
-						Description: desc,
-						Schema:      schema,
-					},
-				},
-			}
+	return pack
+}
 
-			// if request has body, there is no way to distinguish query param and form param.
-			// because they both share the "form" tag, the same param will appear in both query and body.
-			if hasBody && containForm && !containJson && method != http.MethodDelete {
-				operationObject.Consumes = []string{"multipart/form-data", "application/x-www-form-urlencoded"}
+// renderPacks renders the outer packaging responses as definitions,
+// and returns their data field names, the key is the response name.
+// the references in the responses must refer to the types declared in the api file or other responses.
+func renderPacks(d swaggerDefinitionsObject, c *Config, types []spec.Type) (map[string]string, error) {
+	dataKeys := make(map[string]string, len(c.Packs)+1)
+	for _, name := range sortedKeys(c.Packs) {
+		raw := c.Packs[name]
+		resp, dataKey, err := parseResponse(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("parse response pack %s err: %w", name, err)
+		}
+		d[name] = resp
+		dataKeys[name] = dataKey
+	}
 
-				for i := range operationObject.Parameters {
-					if operationObject.Parameters[i].In == "query" {
-						operationObject.Parameters[i].In = "formData"
-					}
-				}
+	if c.Pack != "" {
+		if c.Response != "" {
+			resp, dataKey, err := parseResponse(c.Response)
+			if err != nil {
+				return nil, err
 			}
+			d[c.Pack] = resp
+			dataKeys[c.Pack] = dataKey
+		} else if _, ok := dataKeys[c.Pack]; !ok {
+			d[c.Pack] = defaultResponse
+			dataKeys[c.Pack] = "data"
+		}
+	}
 
-			for _, v := range route.Doc {
-				markerIndex := strings.Index(v, atRespDoc)
-				if markerIndex >= 0 {
-					l := strings.Index(v, "(")
-					r := strings.Index(v, ")")
-					code := strings.TrimSpace(v[markerIndex+len(atRespDoc) : l])
-					var comment string
-					commentIndex := strings.Index(v, "//")
-					if commentIndex > 0 {
-						comment = strings.TrimSpace(strings.Trim(v[commentIndex+2:], "*/"))
-					}
-					content := strings.TrimSpace(v[l+1 : r])
-					if strings.Index(v, ":") > 0 {
-						lines := strings.Split(content, "\n")
-						kv := make(map[string]string, len(lines))
-						for _, line := range lines {
-							sep := strings.Index(line, ":")
-							key := strings.TrimSpace(line[:sep])
-							value := strings.TrimSpace(line[sep+1:])
-							kv[key] = value
-						}
-						kvByte, err := json.Marshal(kv)
-						if err != nil {
-							continue
-						}
-						operationObject.Responses[code] = swaggerResponseObject{
-							Description: comment,
-							Schema: swaggerSchemaObject{
-								schemaCore: schemaCore{
-									Example: string(kvByte),
-								},
-							},
-						}
-					} else if len(content) > 0 {
-						operationObject.Responses[code] = swaggerResponseObject{
-							Description: comment,
-							Schema: swaggerSchemaObject{
-								schemaCore: schemaCore{
-									Ref: "#/definitions/" + content,
-								},
-							},
-						}
-					}
-				}
+	declared := make(map[string]struct{}, len(types)+len(dataKeys))
+	for _, t := range types {
+		declared["#/definitions/"+t.Name()] = struct{}{}
+	}
+	for name := range dataKeys {
+		declared["#/definitions/"+name] = struct{}{}
+	}
+	names := make([]string, 0, len(dataKeys))
+	for name := range dataKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, ref := range schemaRefs(d[name]) {
+			if _, ok := declared[ref]; !ok {
+				return nil, fmt.Errorf("response pack %s refers to undeclared type: %s", name, ref)
 			}
+		}
+	}
 
-			// set OperationID
-			operationObject.OperationID = route.Handler
+	return dataKeys, nil
+}
 
-			for _, param := range operationObject.Parameters {
-				if param.Schema != nil && param.Schema.Ref != "" {
-					requestResponseRefs[param.Schema.Ref] = struct{}{}
-				}
+// streamMediaType returns the media type of the streaming route, empty means the route is not streaming.
+// the route is considered as a server-sent events route when its group is annotated with "sse: true",
+// and the "stream" key of the @doc can specify it per route, it's like this below:
+//
+//	@doc (
+//		stream: "true"                     // server-sent events, same as "text/event-stream"
+//		stream: "application/octet-stream" // chunked download
+//		stream: "false"                    // not streaming, even if the group is annotated with "sse: true"
+//	)
+func streamMediaType(group spec.Group, route spec.Route) string {
+	if v, ok := route.AtDoc.Properties[atDocKeyStream]; ok {
+		v = strings.TrimSpace(strings.Trim(v, "\""))
+		if isStream, err := strconv.ParseBool(v); err == nil {
+			if isStream {
+				return mimeEventStream
 			}
-			operationObject.Summary = strings.ReplaceAll(route.JoinedDoc(), "\"", "")
+			return ""
+		}
+		return v
+	}
 
-			if len(route.AtDoc.Properties) > 0 {
-				operationObject.Description, _ = strconv.Unquote(route.AtDoc.Properties["description"])
-			}
+	if isSSE, _ := strconv.ParseBool(group.GetAnnotation(annotationKeySSE)); isSSE {
+		return mimeEventStream
+	}
 
-			operationObject.Description = strings.ReplaceAll(operationObject.Description, "\"", "")
+	return ""
+}
 
-			if group.GetAnnotation("jwt") != "" ||
-				strings.Contains(strings.ToLower(group.GetAnnotation("middleware")), "jwt") {
-				operationObject.Security = &[]swaggerSecurityRequirementObject{{"apiKey": []string{}}}
-			}
+// renderStreamDefinition describes the event payload of the server-sent events route as a stream definition,
+// and returns the response schema which refers to it.
+func renderStreamDefinition(streamDefinitions swaggerDefinitionsObject, route spec.Route, respSchema schemaCore) swaggerSchemaObject {
+	if route.ResponseType == nil || len(route.ResponseType.Name()) == 0 {
+		return swaggerSchemaObject{schemaCore: schemaCore{Type: "string"}}
+	}
 
-			switch method {
-			case http.MethodGet:
-				pathItemObject.Get = operationObject
-			case http.MethodPost:
-				pathItemObject.Post = operationObject
-			case http.MethodDelete:
-				pathItemObject.Delete = operationObject
-			case http.MethodPut:
-				pathItemObject.Put = operationObject
-			case http.MethodPatch:
-				pathItemObject.Patch = operationObject
-			}
+	name := strings.TrimPrefix(route.ResponseType.Name(), "[]")
+	name = strings.TrimPrefix(name, "*")
+	if strings.HasPrefix(route.ResponseType.Name(), "[]") {
+		name += "List"
+	}
 
-			paths[path] = pathItemObject
+	if _, ok := streamDefinitions[name]; !ok {
+		streamDefinitions[name] = swaggerSchemaObject{
+			schemaCore: schemaCore{Type: "object"},
+			Properties: &swaggerSchemaObjectProperties{
+				{Key: "id", Value: swaggerSchemaObject{schemaCore: schemaCore{Type: "string"}, Description: "event id"}},
+				{Key: "event", Value: swaggerSchemaObject{schemaCore: schemaCore{Type: "string"}, Description: "event type"}},
+				{Key: "data", Value: swaggerSchemaObject{schemaCore: respSchema, Description: "event payload"}},
+			},
+			Title: "Stream result of " + route.ResponseType.Name(),
 		}
 	}
+
+	return swaggerSchemaObject{schemaCore: schemaCore{Ref: streamRefPrefix + name}}
 }
 
 // renderMember collect param property from spec.Member, return whether there exists form fields and json fields.
-func renderMember(pathParamMap map[string]swaggerParameterObject,
-	parameters *swaggerParametersObject, member spec.Member, method string,
-) (containForm, containJson bool) {
+func renderMember(parameters *swaggerParametersObject, member spec.Member, method string) (containForm, containJson bool) {
 	if embedStruct, isEmbed := member.Type.(spec.DefineStruct); isEmbed {
+		start := len(*parameters)
 		for _, m := range embedStruct.Members {
-			f, j := renderMember(pathParamMap, parameters, m, method)
+			f, j := renderMember(parameters, m, method)
 			if f {
 				containForm = true
 			}
@@ -456,6 +1989,11 @@ func renderMember(pathParamMap map[string]swaggerParameterObject,
 				containJson = true
 			}
 		}
+		for i := start; i < len(*parameters); i++ {
+			if (*parameters)[i].origin == "" {
+				(*parameters)[i].origin = embedStruct.Name()
+			}
+		}
 		return containForm, containJson
 	}
 
@@ -476,20 +2014,153 @@ func renderMember(pathParamMap map[string]swaggerParameterObject,
 		containForm = true
 	}
 
-	// overwrite path parameter if we get a user defined one from struct.
-	if op, ok := pathParamMap[p.Name]; p.In == "path" && ok {
-		if p.Description == "" && op.Description != "" {
-			p.Description = op.Description
+	*parameters = append(*parameters, p)
+
+	return containForm, containJson
+}
+
+// parameterCatalog indexes the shared parameters.
+type parameterCatalog struct {
+	declared map[string]string   // location and name of the declared parameter to its definition name
+	embeds   map[string]struct{} // embedded structs which are embedded by multiple request types
+}
+
+// newParameterCatalog indexes the declared parameter definitions,
+// and finds the embedded structs which are embedded by multiple request types if shareEmbeds is enabled.
+func newParameterCatalog(definitions swaggerParameterDefinitionsObject, groups []spec.Group, shareEmbeds bool) parameterCatalog {
+	catalog := parameterCatalog{declared: make(map[string]string), embeds: make(map[string]struct{})}
+	// the first one in name order is referred if multiple ones have the same location and name.
+	for _, name := range sortedKeys(definitions) {
+		p := definitions[name]
+		key := p.In + ":" + p.Name
+		if declared, ok := catalog.declared[key]; ok {
+			warnf("parameter %s is declared by both %s and %s, %s is referred", p.Name, declared, name, declared)
+			continue
 		}
-		delete(pathParamMap, p.Name)
+		catalog.declared[key] = name
+	}
+	if !shareEmbeds {
+		return catalog
 	}
 
-	*parameters = append(*parameters, p)
+	var collectEmbeds func(members []spec.Member, embeds map[string]struct{})
+	collectEmbeds = func(members []spec.Member, embeds map[string]struct{}) {
+		for _, m := range members {
+			if embedStruct, isEmbed := m.Type.(spec.DefineStruct); isEmbed {
+				embeds[embedStruct.Name()] = struct{}{}
+				collectEmbeds(embedStruct.Members, embeds)
+			}
+		}
+	}
 
-	return containForm, containJson
+	counts := make(map[string]int)
+	requestTypes := make(map[string]struct{})
+	for _, group := range groups {
+		for _, route := range group.Routes {
+			defineStruct, ok := route.RequestType.(spec.DefineStruct)
+			if !ok {
+				continue
+			}
+			if _, ok := requestTypes[defineStruct.Name()]; ok {
+				continue
+			}
+			requestTypes[defineStruct.Name()] = struct{}{}
+
+			embeds := make(map[string]struct{})
+			collectEmbeds(defineStruct.Members, embeds)
+			for name := range embeds {
+				counts[name]++
+			}
+		}
+	}
+	for name, count := range counts {
+		if count > 1 {
+			catalog.embeds[name] = struct{}{}
+		}
+	}
+
+	return catalog
+}
+
+// refSharedParameters replaces the header and query parameters which are shared with the references.
+// the parameters of the shared embedded structs are defined by their first occurrences.
+func refSharedParameters(definitions swaggerParameterDefinitionsObject, catalog parameterCatalog,
+	parameters swaggerParametersObject,
+) swaggerParametersObject {
+	for i, p := range parameters {
+		if p.In != "header" && p.In != "query" {
+			continue
+		}
+
+		name, ok := catalog.declared[p.In+":"+p.Name]
+		if !ok {
+			if _, ok := catalog.embeds[p.origin]; !ok {
+				continue
+			}
+			name = p.origin + "." + p.Name
+			if _, ok := definitions[name]; !ok {
+				definitions[name] = p
+			}
+		}
+		parameters[i] = swaggerParameterObject{Ref: "#/parameters/" + name}
+	}
+
+	return parameters
+}
+
+// mergePathParameters puts the path parameters at the head in the order of the path,
+// they are typed from the path members of the request struct, and fall back to string when no member matches.
+// it warns about the path parameter without matching member and the path member without matching parameter.
+func mergePathParameters(route spec.Route, pathParams, parameters swaggerParametersObject) swaggerParametersObject {
+	members := make(map[string]swaggerParameterObject)
+	var memberNames []string
+	merged := make(swaggerParametersObject, 0, len(parameters)+len(pathParams))
+	others := make(swaggerParametersObject, 0, len(parameters))
+	for _, p := range parameters {
+		if p.In == "path" {
+			members[p.Name] = p
+			memberNames = append(memberNames, p.Name)
+		} else {
+			others = append(others, p)
+		}
+	}
+
+	for _, pp := range pathParams {
+		p, ok := members[pp.Name]
+		if !ok {
+			warnf("path parameter %q has no matching path member in the request type, route: %s %s",
+				pp.Name, route.Method, route.Path)
+			merged = append(merged, pp)
+			continue
+		}
+
+		// overwrite path parameter if we get a user defined one from struct.
+		if p.Description == "" && pp.Description != "" {
+			p.Description = pp.Description
+		}
+		p.Required = true
+		merged = append(merged, p)
+		delete(members, p.Name)
+	}
+
+	for _, name := range memberNames {
+		if _, ok := members[name]; ok {
+			warnf("path member %q has no matching path parameter in the route, route: %s %s",
+				name, route.Method, route.Path)
+		}
+	}
+
+	return append(merged, others...)
 }
 
 func fillValidateOption(s *swaggerSchemaObject, opt string) {
+	if format, ok := validateFormats[opt]; ok {
+		if s.Type == "string" {
+			s.Format = format
+		}
+		return
+	}
+
 	kv := strings.SplitN(opt, "=", 2)
 	if len(kv) != 2 {
 		return
@@ -583,11 +2254,19 @@ func renderStruct(member spec.Member) swaggerParameterObject {
 		format = "UNKNOWN"
 	}
 	sp := swaggerParameterObject{In: "", Type: ftype, Format: format, Schema: new(swaggerSchemaObject)}
+	isArray := ok && strings.HasPrefix(member.Type.Name(), "[]")
+	if isArray {
+		sp.Type, sp.Format = "array", ""
+		sp.Items = &swaggerItemsObject{schemaCore: schemaCore{Type: ftype, Format: format}}
+	}
+	sp.Schema.Type = sp.Type
 
 	for _, tag := range member.Tags() {
 		switch tag.Key {
 		case tagKeyHeader:
 			sp.In = "header"
+		case tagKeyCookie:
+			sp.In = "cookie"
 		case tagKeyPath:
 			sp.In = "path"
 		case tagKeyForm:
@@ -613,7 +2292,8 @@ func renderStruct(member spec.Member) swaggerParameterObject {
 			if strings.HasPrefix(option, optionsOption) {
 				segs := strings.SplitN(option, equalToken, 2)
 				if len(segs) == 2 {
-					sp.Enum = strings.Split(segs[1], optionSeparator)
+					sp.Schema.Enum = strings.Split(segs[1], optionSeparator)
+					sp.Enum = sp.Schema.Enum
 				}
 			}
 
@@ -651,9 +2331,12 @@ func renderStruct(member spec.Member) swaggerParameterObject {
 		sp.Name = member.Name
 	}
 
-	if len(member.Comment) > 0 {
-		sp.Description = strings.TrimSpace(strings.ReplaceAll(strings.TrimLeft(member.Comment, "/"), "\\n", "\n"))
+	sp.Description = memberDescription(member)
+	if deprecated, reason := memberDeprecation(member); deprecated {
+		sp.Deprecated = true
+		sp.Description = appendDeprecation(sp.Description, reason)
 	}
+	sp.Extensions = memberExtensions(member)
 
 	// schema is defined when "in" == "body"
 	if sp.In != "body" {
@@ -661,10 +2344,17 @@ func renderStruct(member spec.Member) swaggerParameterObject {
 		sp.Schema = nil
 	}
 
+	// enumerations restrict the items of the array
+	if isArray {
+		sp.Items.Enum, sp.Enum = sp.Enum, nil
+	}
+
 	return sp
 }
 
-func renderReplyAsDefinition(d swaggerDefinitionsObject, p []spec.Type, _ refMap) {
+// renderReplyAsDefinition renders the types as definitions, formLocations records where the form members
+// of the request types are located on the routes with body, so that the definitions agree with the parameters.
+func renderReplyAsDefinition(d swaggerDefinitionsObject, p []spec.Type, _ refMap, formLocations map[string]string) {
 	// record inline struct
 	inlineMap := make(map[string][]string)
 	for _, i2 := range p {
@@ -679,11 +2369,13 @@ func renderReplyAsDefinition(d swaggerDefinitionsObject, p []spec.Type, _ refMap
 		defineStruct, _ := i2.(spec.DefineStruct)
 
 		schema.Title = defineStruct.Name()
+		schema.Description = docText(withoutExtensionDocs(defineStruct.Docs))
+		schema.Extensions = docExtensions(defineStruct.Docs)
 
 		for _, member := range defineStruct.Members {
 			inlines := collectProperties(schema.Properties, &formFields, &untaggedFields, member)
 			if len(inlines) > 0 {
-				inlineMap[defineStruct.Name()] = inlines
+				inlineMap[defineStruct.Name()] = append(inlineMap[defineStruct.Name()], inlines...)
 			}
 			for _, tag := range member.Tags() {
 				if tag.Key != tagKeyForm && tag.Key != tagKeyJson {
@@ -711,37 +2403,73 @@ func renderReplyAsDefinition(d swaggerDefinitionsObject, p []spec.Type, _ refMap
 				}
 			}
 		}
-		// if there exists any json fields, form fields are ignored (considered to be params in query).
-		if len(*schema.Properties) == 0 && len(formFields) > 0 {
+		switch formLocations[i2.Name()] {
+		case formInQuery:
+			// form fields are params in query.
+		case formInBody:
+			// form fields are sent in the body, json fields are not bound at all.
 			*schema.Properties = formFields
+		default:
+			// if there exists any json fields, form fields are ignored (considered to be params in query).
+			if len(*schema.Properties) == 0 && len(formFields) > 0 {
+				*schema.Properties = formFields
+			}
 		}
 		if len(untaggedFields) > 0 {
 			*schema.Properties = append(*schema.Properties, untaggedFields...)
 		}
 
+		// only the rendered properties are required
+		required := schema.Required[:0]
+		for _, name := range schema.Required {
+			for _, kv := range *schema.Properties {
+				if kv.Key == name {
+					required = append(required, name)
+					break
+				}
+			}
+		}
+		schema.Required = required
+
 		d[i2.Name()] = schema
 	}
 
-	// inherit properties
-	for name, inlines := range inlineMap {
-		if baseStruct, ok := d[name]; ok {
-			tmp := new(swaggerSchemaObjectProperties)
-			for _, inlineName := range inlines {
-				if inlineStruct, ok := d[inlineName]; ok {
-					*tmp = append(*tmp, *inlineStruct.Properties...)
-				}
-			}
-			// append from the head
-			if len(*tmp) > 0 {
-				*baseStruct.Properties = append(*tmp, *baseStruct.Properties...)
-			}
+	// inherit properties in declaration order, the inline structs inherit their own inline structs first,
+	// so that the output does not depend on the map iteration order.
+	inherited := make(map[string]bool, len(inlineMap))
+	for _, i2 := range p {
+		inheritProperties(d, inlineMap, i2.Name(), inherited)
+	}
+}
+
+// inheritProperties prepends the properties of the inline structs to the struct of the name,
+// inherited records the visited structs to avoid repeated and circular inheritance.
+func inheritProperties(d swaggerDefinitionsObject, inlineMap map[string][]string, name string, inherited map[string]bool) {
+	if inherited[name] {
+		return
+	}
+	inherited[name] = true
+
+	baseStruct, ok := d[name]
+	if !ok {
+		return
+	}
+	tmp := new(swaggerSchemaObjectProperties)
+	for _, inlineName := range inlineMap[name] {
+		inheritProperties(d, inlineMap, inlineName, inherited)
+		if inlineStruct, ok := d[inlineName]; ok {
+			*tmp = append(*tmp, *inlineStruct.Properties...)
 		}
 	}
+	// append from the head
+	if len(*tmp) > 0 {
+		*baseStruct.Properties = append(*tmp, *baseStruct.Properties...)
+	}
 }
 
 func collectProperties(jsonFields, formFields, untaggedFields *swaggerSchemaObjectProperties, member spec.Member) (inlines []string) {
 	in := fieldIn(member)
-	if in == tagKeyHeader || in == tagKeyPath {
+	if in == tagKeyHeader || in == tagKeyPath || in == tagKeyCookie {
 		return inlines
 	}
 
@@ -780,7 +2508,8 @@ func collectProperties(jsonFields, formFields, untaggedFields *swaggerSchemaObje
 
 func fieldIn(member spec.Member) string {
 	for _, tag := range member.Tags() {
-		if tag.Key == tagKeyPath || tag.Key == tagKeyHeader || tag.Key == tagKeyForm || tag.Key == tagKeyJson {
+		if tag.Key == tagKeyPath || tag.Key == tagKeyHeader || tag.Key == tagKeyCookie ||
+			tag.Key == tagKeyForm || tag.Key == tagKeyJson {
 			return tag.Key
 		}
 	}
@@ -796,8 +2525,7 @@ func schemaOfField(member spec.Member) swaggerSchemaObject {
 	kind := swaggerMapTypes[member.Type.Name()]
 	var props *swaggerSchemaObjectProperties
 
-	comment := member.GetComment()
-	comment = strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(comment, "//", ""), "\\n", "\n"))
+	comment := memberDescription(member)
 
 	switch ft := kind; ft {
 	case reflect.Invalid: // []Struct 也有可能是 Struct
@@ -820,9 +2548,9 @@ func schemaOfField(member spec.Member) swaggerSchemaObject {
 			tempKind := swaggerMapTypes[strings.ReplaceAll(refTypeName, "[]", "")]
 			ftype, format, ok := primitiveSchema(tempKind, refTypeName)
 			if ok {
-				core.Items = &swaggerItemsObject{Type: ftype, Format: format}
+				core.Items = &swaggerItemsObject{schemaCore: schemaCore{Type: ftype, Format: format}}
 			} else {
-				core.Items = &swaggerItemsObject{Type: ft.String(), Format: "UNKNOWN"}
+				core.Items = &swaggerItemsObject{schemaCore: schemaCore{Type: ft.String(), Format: "UNKNOWN"}}
 			}
 		} else {
 			core = schemaCore{
@@ -852,7 +2580,7 @@ func schemaOfField(member spec.Member) swaggerSchemaObject {
 		ret = swaggerSchemaObject{
 			schemaCore: schemaCore{
 				Type:  "array",
-				Items: (*swaggerItemsObject)(&core),
+				Items: &swaggerItemsObject{schemaCore: core},
 			},
 		}
 	case reflect.Invalid:
@@ -861,7 +2589,7 @@ func schemaOfField(member spec.Member) swaggerSchemaObject {
 			ret = swaggerSchemaObject{
 				schemaCore: schemaCore{
 					Type:  "array",
-					Items: (*swaggerItemsObject)(&core),
+					Items: &swaggerItemsObject{schemaCore: core},
 				},
 			}
 		} else {
@@ -877,6 +2605,11 @@ func schemaOfField(member spec.Member) swaggerSchemaObject {
 		}
 	}
 	ret.Description = comment
+	if deprecated, reason := memberDeprecation(member); deprecated {
+		ret.Deprecated = true
+		ret.Description = appendDeprecation(ret.Description, reason)
+	}
+	ret.Extensions = memberExtensions(member)
 
 	for _, tag := range member.Tags() {
 		if tag.Key == tagKeyValidate {
@@ -957,6 +2690,11 @@ func primitiveSchema(kind reflect.Kind, t string) (ftype, format string, ok bool
 	}
 }
 
+// warnf writes the warning to the stderr, so that it's not mixed into the normal output.
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "goctl-swagger: warning: "+format+"\n", args...)
+}
+
 // StringToBytes converts string to byte slice without a memory allocation.
 func stringToBytes(s string) (b []byte) {
 	return *(*[]byte)(unsafe.Pointer(
@@ -994,7 +2732,7 @@ func parseResponse(resp string) (swaggerSchemaObject, string, error) {
 	hasData := false
 	dataKey := ""
 	for _, field := range fields {
-		if field.Name == "" || field.Type == "" {
+		if field.Name == "" || field.Type == "" && field.Ref == "" && len(field.AllOf) == 0 {
 			return swaggerSchemaObject{}, "", errors.New("响应字段参数错误")
 		}
 		if field.IsData {
@@ -1009,21 +2747,185 @@ func parseResponse(resp string) (swaggerSchemaObject, string, error) {
 	properties := new(swaggerSchemaObjectProperties)
 	response := swaggerSchemaObject{schemaCore: schemaCore{Type: "object"}}
 	for _, field := range fields {
-		*properties = append(*properties,
-			keyVal{
-				Key: field.Name,
-				Value: swaggerSchemaObject{
-					schemaCore:  schemaCore{Type: field.Type},
-					Description: field.Description,
-					Example:     field.Example,
-				},
-			})
+		schema := field.swaggerSchemaObject
+		resolveSchemaRefs(&schema)
+		*properties = append(*properties, keyVal{Key: field.Name, Value: schema})
+		if field.Required {
+			response.Required = append(response.Required, field.Name)
+		}
 	}
 	response.Properties = properties
 
 	return response, dataKey, nil
 }
 
+// pruneDefinitions removes the definitions which are not transitively referenced by the operations,
+// the shared parameters, the stream definitions and the pack wrappers,
+// and returns the api types which are declared but not used by any route of the service.
+func pruneDefinitions(s *swaggerObject, service spec.Service, types []spec.Type, dataKeys map[string]string) []string {
+	var refs []string
+	for _, item := range s.Paths {
+		for _, op := range []*swaggerOperationObject{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil {
+				continue
+			}
+			for _, param := range op.Parameters {
+				if param.Schema != nil {
+					refs = append(refs, schemaRefs(*param.Schema)...)
+				}
+			}
+			for _, resp := range op.Responses {
+				refs = append(refs, schemaRefs(resp.Schema)...)
+			}
+		}
+	}
+	for _, param := range s.Parameters {
+		if param.Schema != nil {
+			refs = append(refs, schemaRefs(*param.Schema)...)
+		}
+	}
+	for _, schema := range s.StreamDefinitions {
+		refs = append(refs, schemaRefs(schema)...)
+	}
+	for name := range dataKeys {
+		refs = append(refs, resolveRef(strings.TrimPrefix(name, "/")))
+	}
+
+	reachable := make(map[string]struct{}, len(s.Definitions))
+	for len(refs) > 0 {
+		name := strings.TrimPrefix(refs[len(refs)-1], "#/definitions/")
+		refs = refs[:len(refs)-1]
+		if _, ok := reachable[name]; ok {
+			continue
+		}
+		schema, ok := s.Definitions[name]
+		if !ok {
+			continue
+		}
+		reachable[name] = struct{}{}
+		refs = append(refs, schemaRefs(schema)...)
+	}
+
+	// the embedded structs are inlined, so they are used but not referenced.
+	structs := make(map[string]spec.DefineStruct, len(types))
+	for _, t := range types {
+		if ds, ok := t.(spec.DefineStruct); ok {
+			structs[ds.Name()] = ds
+		}
+	}
+	used := make(map[string]struct{}, len(reachable))
+	var use func(t spec.Type)
+	use = func(t spec.Type) {
+		switch v := t.(type) {
+		case spec.DefineStruct:
+			if _, ok := used[v.Name()]; ok {
+				return
+			}
+			used[v.Name()] = struct{}{}
+			for _, member := range structs[v.Name()].Members {
+				use(member.Type)
+			}
+		case spec.PointerType:
+			use(v.Type)
+		case spec.ArrayType:
+			use(v.Value)
+		case spec.MapType:
+			use(v.Value)
+		}
+	}
+	// the requests of the query, path, header or form members only are used but not referenced either.
+	for _, group := range service.Groups {
+		for _, route := range group.Routes {
+			use(route.RequestType)
+			use(route.ResponseType)
+		}
+	}
+	for name := range reachable {
+		if ds, ok := structs[name]; ok {
+			use(ds)
+		}
+	}
+
+	var unused []string
+	for name := range structs {
+		if _, ok := used[name]; !ok {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+
+	for name := range s.Definitions {
+		if _, ok := reachable[name]; !ok {
+			delete(s.Definitions, name)
+		}
+	}
+
+	return unused
+}
+
+// reportUnusedTypes warns about the api types which are not used by any route.
+func reportUnusedTypes(names []string) {
+	if len(names) > 0 {
+		warnf("api types are not used by any route and are pruned: %s", strings.Join(names, ", "))
+	}
+}
+
+// resolveSchemaRefs completes the short references to the definitions, e.g. "Pagination" to "#/definitions/Pagination".
+func resolveSchemaRefs(s *swaggerSchemaObject) {
+	s.Ref = resolveRef(s.Ref)
+	if s.Items != nil {
+		resolveSchemaRefs((*swaggerSchemaObject)(s.Items))
+	}
+	if s.Properties != nil {
+		for i, kv := range *s.Properties {
+			if v, ok := kv.Value.(swaggerSchemaObject); ok {
+				resolveSchemaRefs(&v)
+				(*s.Properties)[i].Value = v
+			}
+		}
+	}
+	if s.AdditionalProperties != nil {
+		resolveSchemaRefs(s.AdditionalProperties)
+	}
+	for i := range s.AllOf {
+		resolveSchemaRefs(&s.AllOf[i])
+	}
+}
+
+func resolveRef(ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "#/") {
+		return ref
+	}
+
+	return "#/definitions/" + ref
+}
+
+// schemaRefs returns all references in the schema.
+func schemaRefs(s swaggerSchemaObject) []string {
+	var refs []string
+	if s.Ref != "" {
+		refs = append(refs, s.Ref)
+	}
+	if s.Items != nil {
+		refs = append(refs, schemaRefs(swaggerSchemaObject(*s.Items))...)
+	}
+	if s.Properties != nil {
+		for _, kv := range *s.Properties {
+			if v, ok := kv.Value.(swaggerSchemaObject); ok {
+				refs = append(refs, schemaRefs(v)...)
+			}
+		}
+	}
+	if s.AdditionalProperties != nil {
+		refs = append(refs, schemaRefs(*s.AdditionalProperties)...)
+	}
+	for _, v := range s.AllOf {
+		refs = append(refs, schemaRefs(v)...)
+	}
+
+	return refs
+}
+
 func parseDefaultResponse() swaggerSchemaObject {
 	response, _, _ := parseResponse(DefaultResponseJson)
 	return response
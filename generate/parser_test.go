@@ -0,0 +1,458 @@
+package generate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequestLocations(t *testing.T) {
+	const api = `syntax = "v1"
+
+type (
+	MixedReq {
+		Page int    ` + "`form:\"page\"`" + `
+		Name string ` + "`json:\"name\"`" + `
+	}
+	FormReq {
+		Page int    ` + "`form:\"page\"`" + `
+		Size int    ` + "`form:\"size\"`" + `
+	}
+)
+
+service demo {
+	@handler mixed
+	post /mixed (MixedReq)
+
+	@doc (
+		form_in: "body"
+	)
+	@handler mixedBody
+	post /mixed/body (MixedReq)
+
+	@doc (
+		consumes: "application/x-www-form-urlencoded"
+	)
+	@handler mixedForm
+	post /mixed/form (MixedReq)
+
+	@handler form
+	post /form (FormReq)
+
+	@doc (
+		form_in: "query"
+		consumes: "application/json"
+	)
+	@handler mixedQuery
+	put /mixed/query (MixedReq)
+
+	@doc (
+		form_in: "body"
+	)
+	@handler formBody
+	delete /form/body (FormReq)
+}
+
+@server (
+	form_in: body
+)
+service demo {
+	@doc (
+		form_in: "query"
+	)
+	@handler mixedOverride
+	patch /mixed/override (MixedReq)
+}`
+
+	cases := []struct {
+		name    string
+		handler string
+		method  string
+		path    string
+		ins     map[string]string
+		props   []string
+		wantErr string
+	}{
+		{name: "json and form", handler: "mixed", path: "/mixed", ins: map[string]string{"page": "query", "body": "body"}},
+		{name: "form only", handler: "form", path: "/form", ins: map[string]string{"page": "formData", "size": "formData"}},
+		{name: "form in query", handler: "mixedQuery", method: "put", path: "/mixed/query", ins: map[string]string{"page": "query", "body": "body"}, props: []string{"name"}},
+		{name: "form in body of delete", handler: "formBody", method: "delete", path: "/form/body", ins: map[string]string{"page": "formData", "size": "formData"}},
+		{name: "route overrides group", handler: "mixedOverride", method: "patch", path: "/mixed/override", ins: map[string]string{"page": "query", "body": "body"}, props: []string{"name"}},
+		{name: "json with form in body", handler: "mixedBody", path: "/mixed/body", wantErr: "route: post /mixed/body"},
+		{name: "json with form media type", handler: "mixedForm", path: "/mixed/form", wantErr: "route: post /mixed/form"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// only the route of the case is rendered, so that the other invalid routes do not fail it.
+			s, err := generateJSON(t, api, &Config{Include: "handler:" + tc.handler})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want error with %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			method := tc.method
+			if method == "" {
+				method = "post"
+			}
+			ins := parameterIns(lookup(s, "paths", tc.path, method))
+			if len(ins) != len(tc.ins) {
+				t.Fatalf("want parameters %v, got %v", tc.ins, ins)
+			}
+			for name, in := range tc.ins {
+				if ins[name] != in {
+					t.Errorf("want %s in %s, got %q", name, in, ins[name])
+				}
+			}
+			// the definition of the request agrees with the parameters.
+			if tc.props != nil {
+				var def string
+				for name := range lookup(s, "definitions").(map[string]interface{}) {
+					if strings.HasSuffix(name, "Req") {
+						def = name
+					}
+				}
+				props, _ := lookup(s, "definitions", def, "properties").(map[string]interface{})
+				if len(props) != len(tc.props) {
+					t.Fatalf("want properties %v of %s, got %v", tc.props, def, props)
+				}
+				for _, name := range tc.props {
+					if _, ok := props[name]; !ok {
+						t.Errorf("want property %s of %s", name, def)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestExtensionValue(t *testing.T) {
+	cases := []struct {
+		value string
+		want  string
+	}{
+		{value: `true`, want: `true`},
+		{value: `{"a": 1}`, want: `{"a": 1}`},
+		{value: `{'a': 'b'}`, want: `{"a": "b"}`},
+		{value: `['it\'s', "it's"]`, want: `["it's", "it's"]`},
+		{value: `{'say': 'a "word"'}`, want: `{"say": "a \"word\""}`},
+		{value: `['it's']`, want: `"['it's']"`},
+		{value: `it's`, want: `"it's"`},
+		{value: ``, want: `""`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			if got := string(extensionValue(tc.value)); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseResponseItems(t *testing.T) {
+	resp, dataKey, err := parseResponse(`[
+		{"name": "code", "type": "integer", "required": true},
+		{"name": "errors", "type": "array", "items": {"type": "object", "required": ["field"],
+			"properties": {"field": {"type": "string"}, "causes": {"type": "array", "items": {"$ref": "Cause"}}}}},
+		{"name": "data", "type": "object", "is_data": true}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataKey != "data" {
+		t.Fatalf("want data key data, got %s", dataKey)
+	}
+
+	got := decodeJSON(t, resp)
+	items := lookup(got, "properties", "errors", "items")
+	if lookup(items, "type") != "object" {
+		t.Fatalf("want object items, got %v", items)
+	}
+	if lookup(items, "properties", "field", "type") != "string" {
+		t.Errorf("want the field property of the items, got %v", items)
+	}
+	if ref := lookup(items, "properties", "causes", "items", "$ref"); ref != "#/definitions/Cause" {
+		t.Errorf("want the resolved reference of the nested items, got %v", ref)
+	}
+	if required, _ := lookup(items, "required").([]interface{}); len(required) != 1 || required[0] != "field" {
+		t.Errorf("want the required fields of the items, got %v", required)
+	}
+	if refs := schemaRefs(resp); len(refs) != 1 || refs[0] != "#/definitions/Cause" {
+		t.Errorf("want the references of the nested items, got %v", refs)
+	}
+}
+
+func TestRouteOperationID(t *testing.T) {
+	const api = `syntax = "v1"
+
+@server (
+	prefix: /v1
+)
+service demo-api {
+	@handler getV1
+	get /orders/:id
+}
+
+@server (
+	prefix: /v2
+)
+service demo-api {
+	@handler getV2
+	get /orders/:id
+}
+
+@server (
+	group: order
+)
+service demo-api {
+	@handler list
+	get /orders
+}
+
+service demo-api {
+	@handler ping
+	get /ping
+
+	@doc (
+		operationId: "healthCheck"
+	)
+	@handler health
+	get /health
+}`
+
+	cases := []struct {
+		strategy string
+		want     map[string]string
+	}{
+		{
+			strategy: operationIDHandler,
+			want:     map[string]string{"/v1/orders/{id}": "getV1", "/orders": "list", "/ping": "ping", "/health": "healthCheck"},
+		},
+		{
+			strategy: operationIDGroupHandler,
+			want:     map[string]string{"/v1/orders/{id}": "v1_getV1", "/orders": "order_list", "/ping": "demo_api_ping"},
+		},
+		{
+			strategy: operationIDMethodPath,
+			want:     map[string]string{"/v1/orders/{id}": "get_v1_orders_id", "/orders": "get_orders", "/health": "healthCheck"},
+		},
+		{
+			// the prefixes tell the groups apart without the numeric suffixes.
+			strategy: "{group}_{method}",
+			want:     map[string]string{"/v1/orders/{id}": "v1_get", "/v2/orders/{id}": "v2_get", "/orders": "order_get"},
+		},
+		{
+			strategy: "{method}",
+			want:     map[string]string{"/v1/orders/{id}": "get", "/v2/orders/{id}": "get_2"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			s, err := generateJSON(t, api, &Config{OperationID: tc.strategy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tc.want {
+				if got := lookup(s, "paths", path, "get", "operationId"); got != want {
+					t.Errorf("want operationId %s of %s, got %v", want, path, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	const api = `syntax = "v1"
+
+@server (
+	group: order
+)
+service demo {
+	@handler listOrders
+	get /orders
+}
+
+@server (
+	group: user
+)
+service demo {
+	@handler listUsers
+	get /users
+}`
+
+	c := func(include string) *Config {
+		return &Config{
+			TagNaming: tagNamingPlain,
+			Include:   include,
+			Tags:      json.RawMessage(`[{"name": "user", "description": "用户"}, {"name": "order", "description": "订单"}, {"name": "unused"}]`),
+			TagGroups: json.RawMessage(`[{"name": "账户", "tags": ["user", "unused"]}, {"name": "交易", "tags": ["order"]}]`),
+		}
+	}
+	cases := []struct {
+		name      string
+		include   string
+		tags      []string
+		tagGroups []string
+	}{
+		{name: "unfiltered", tags: []string{"user", "order", "unused"}, tagGroups: []string{"账户:user,unused", "交易:order"}},
+		{name: "filtered", include: "group:order", tags: []string{"order"}, tagGroups: []string{"交易:order"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := applyGenerate(newTestPlugin(t, api, nil), c(tc.include))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var tags, tagGroups []string
+			for _, tag := range s.Tags {
+				tags = append(tags, tag.Name)
+			}
+			for _, tagGroup := range s.TagGroups {
+				tagGroups = append(tagGroups, tagGroup.Name+":"+strings.Join(tagGroup.Tags, ","))
+			}
+			if !reflect.DeepEqual(tags, tc.tags) {
+				t.Errorf("want tags %v, got %v", tc.tags, tags)
+			}
+			if !reflect.DeepEqual(tagGroups, tc.tagGroups) {
+				t.Errorf("want tag groups %v, got %v", tc.tagGroups, tagGroups)
+			}
+		})
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	const api = `syntax = "v1"
+
+type (
+	ListReq {
+		Old  string ` + "`form:\"old,optional\"`" + ` // Deprecated: use new
+		New  string ` + "`form:\"new,optional\"`" + `
+		Flag bool   ` + "`form:\"flag,optional,deprecated\"`" + `
+	}
+	Order {
+		Id    int64  ` + "`json:\"id\"`" + `
+		Code  string ` + "`json:\"code\"`" + ` // Deprecated: use id
+		Extra string ` + "`json:\"extra,optional,deprecated\"`" + `
+	}
+)
+
+service demo {
+	@doc (
+		summary: "list orders"
+		deprecated: "use /v2/orders; removal 2027-01"
+	)
+	@handler listOrders
+	get /orders (ListReq) returns (Order)
+
+	@doc (
+		deprecated: "true"
+		sunset: "2027-01-31"
+	)
+	@handler getOrder
+	get /orders/:id returns (Order)
+
+	@doc (
+		deprecated: "false"
+	)
+	@handler createOrder
+	post /orders (Order)
+
+	@handler deleteOrder
+	delete /orders/:id
+}`
+
+	s, err := generateJSON(t, api, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("route", func(t *testing.T) {
+		cases := []struct {
+			path, method string
+			deprecated   interface{}
+			desc         interface{}
+			sunset       interface{}
+		}{
+			{path: "/orders", method: "get", deprecated: true, desc: "Deprecated: use /v2/orders; removal 2027-01", sunset: "2027-01"},
+			{path: "/orders/{id}", method: "get", deprecated: true, desc: "Deprecated", sunset: "2027-01-31"},
+			{path: "/orders", method: "post"},
+			{path: "/orders/{id}", method: "delete"},
+		}
+		for _, tc := range cases {
+			op := lookup(s, "paths", tc.path, tc.method)
+			if op == nil {
+				t.Fatalf("want operation %s %s", tc.method, tc.path)
+			}
+			if got := lookup(op, "deprecated"); got != tc.deprecated {
+				t.Errorf("%s %s: want deprecated %v, got %v", tc.method, tc.path, tc.deprecated, got)
+			}
+			if got := lookup(op, "description"); got != tc.desc {
+				t.Errorf("%s %s: want description %q, got %q", tc.method, tc.path, tc.desc, got)
+			}
+			if got := lookup(op, "x-sunset"); got != tc.sunset {
+				t.Errorf("%s %s: want x-sunset %v, got %v", tc.method, tc.path, tc.sunset, got)
+			}
+		}
+	})
+
+	t.Run("parameter", func(t *testing.T) {
+		params := map[string]interface{}{}
+		list, _ := lookup(s, "paths", "/orders", "get", "parameters").([]interface{})
+		for _, p := range list {
+			name, _ := lookup(p, "name").(string)
+			params[name] = p
+		}
+		cases := []struct {
+			name       string
+			deprecated interface{}
+			desc       interface{}
+		}{
+			{name: "old", deprecated: true, desc: "Deprecated: use new"},
+			{name: "new"},
+			{name: "flag", deprecated: true, desc: "Deprecated"},
+		}
+		for _, tc := range cases {
+			p := params[tc.name]
+			if p == nil {
+				t.Fatalf("want parameter %s", tc.name)
+			}
+			if got := lookup(p, "x-deprecated"); got != tc.deprecated {
+				t.Errorf("%s: want x-deprecated %v, got %v", tc.name, tc.deprecated, got)
+			}
+			if got := lookup(p, "deprecated"); got != nil {
+				t.Errorf("%s: want no deprecated field in swagger 2.0, got %v", tc.name, got)
+			}
+			if got := lookup(p, "description"); got != tc.desc {
+				t.Errorf("%s: want description %q, got %q", tc.name, tc.desc, got)
+			}
+		}
+	})
+
+	t.Run("property", func(t *testing.T) {
+		cases := []struct {
+			name       string
+			deprecated interface{}
+			desc       interface{}
+		}{
+			{name: "id"},
+			{name: "code", deprecated: true, desc: "Deprecated: use id"},
+			{name: "extra", deprecated: true, desc: "Deprecated"},
+		}
+		for _, tc := range cases {
+			prop := lookup(s, "definitions", "Order", "properties", tc.name)
+			if prop == nil {
+				t.Fatalf("want property %s", tc.name)
+			}
+			if got := lookup(prop, "x-deprecated"); got != tc.deprecated {
+				t.Errorf("%s: want x-deprecated %v, got %v", tc.name, tc.deprecated, got)
+			}
+			if got := lookup(prop, "description"); got != tc.desc {
+				t.Errorf("%s: want description %q, got %q", tc.name, tc.desc, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	sortDeclaration  = "declaration"
+	sortAlphabetical = "alphabetical"
+)
+
+// parameterLocations is the order of the parameter locations in the alphabetical mode.
+var parameterLocations = []string{"path", "query", "header", "formData", "body"}
+
+// sortSwagger sorts the swagger object by the sort mode, the objects are kept in the declaration order by default,
+// the alphabetical mode sorts the tags, the operation parameters by location and name,
+// and the properties and the required fields of the schemas by name.
+// the maps are always marshaled in key order, so the paths, the definitions and the responses are sorted anyway.
+func sortSwagger(s *swaggerObject, mode string) error {
+	switch mode {
+	case "", sortDeclaration:
+		return nil
+	case sortAlphabetical:
+	default:
+		return fmt.Errorf("unsupported sort: %s, only support [%s %s]", mode, sortDeclaration, sortAlphabetical)
+	}
+
+	sort.SliceStable(s.Tags, func(i, j int) bool {
+		return s.Tags[i].Name < s.Tags[j].Name
+	})
+
+	for _, item := range s.Paths {
+		for _, op := range []*swaggerOperationObject{
+			item.Get, item.Delete, item.Post, item.Put, item.Patch,
+		} {
+			if op == nil {
+				continue
+			}
+			sortParameters(op.Parameters, s.Parameters)
+			for code, resp := range op.Responses {
+				sortSchema(&resp.Schema)
+				op.Responses[code] = resp
+			}
+			for _, param := range op.Parameters {
+				if param.Schema != nil {
+					sortSchema(param.Schema)
+				}
+			}
+		}
+	}
+	for name, schema := range s.Definitions {
+		sortSchema(&schema)
+		s.Definitions[name] = schema
+	}
+	for name, schema := range s.StreamDefinitions {
+		sortSchema(&schema)
+		s.StreamDefinitions[name] = schema
+	}
+
+	return nil
+}
+
+// sortParameters sorts the parameters by location and name, the references are sorted by the referred ones.
+func sortParameters(params swaggerParametersObject, shared swaggerParameterDefinitionsObject) {
+	key := func(param swaggerParameterObject) (int, string) {
+		if param.Ref != "" {
+			param = shared[strings.TrimPrefix(param.Ref, "#/parameters/")]
+		}
+		for i, in := range parameterLocations {
+			if in == param.In {
+				return i, param.Name
+			}
+		}
+		return len(parameterLocations), param.Name
+	}
+	sort.SliceStable(params, func(i, j int) bool {
+		li, ni := key(params[i])
+		lj, nj := key(params[j])
+		if li != lj {
+			return li < lj
+		}
+		return ni < nj
+	})
+}
+
+// sortSchema sorts the properties and the required fields of the schema by name recursively.
+func sortSchema(schema *swaggerSchemaObject) {
+	sort.Strings(schema.Required)
+	if schema.Properties != nil {
+		props := make(swaggerSchemaObjectProperties, len(*schema.Properties))
+		copy(props, *schema.Properties)
+		sort.SliceStable(props, func(i, j int) bool {
+			return props[i].Key < props[j].Key
+		})
+		for i, kv := range props {
+			if prop, ok := kv.Value.(swaggerSchemaObject); ok {
+				sortSchema(&prop)
+				props[i].Value = prop
+			}
+		}
+		schema.Properties = &props
+	}
+	if schema.Items != nil {
+		sortSchema((*swaggerSchemaObject)(schema.Items))
+	}
+	if schema.AdditionalProperties != nil {
+		sortSchema(schema.AdditionalProperties)
+	}
+	for i := range schema.AllOf {
+		sortSchema(&schema.AllOf[i])
+	}
+}
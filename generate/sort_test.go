@@ -0,0 +1,127 @@
+package generate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const sortAPI = `syntax = "v1"
+
+type (
+	Req {
+		Zone  string ` + "`form:\"zone\"`" + `
+		Trace string ` + "`header:\"X-Trace\"`" + `
+		Area  string ` + "`form:\"area\"`" + `
+		Id    int64  ` + "`path:\"id\"`" + `
+	}
+	Resp {
+		Zeta  string ` + "`json:\"zeta\"`" + `
+		Alpha string ` + "`json:\"alpha\"`" + `
+	}
+)
+
+@server (
+	group: zoo
+)
+service demo {
+	@doc (
+		tags: "ant"
+	)
+	@handler get
+	get /items/:id (Req) returns (Resp)
+
+	@handler list
+	get /items returns (Resp)
+}`
+
+func TestSortSwagger(t *testing.T) {
+	cases := []struct {
+		name   string
+		sort   string
+		params []string
+		props  []string
+		tags   []string
+	}{
+		{
+			name:   "declaration",
+			params: []string{"id", "zone", "X-Trace", "area"},
+			props:  []string{"zeta", "alpha"},
+			tags:   []string{"demo/zoo", "ant"},
+		},
+		{
+			name:   "alphabetical",
+			sort:   sortAlphabetical,
+			params: []string{"id", "area", "zone", "X-Trace"},
+			props:  []string{"alpha", "zeta"},
+			tags:   []string{"ant", "demo/zoo"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := applyGenerate(newTestPlugin(t, sortAPI, nil), &Config{Sort: tc.sort})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var params []string
+			for _, p := range s.Paths["/items/{id}"].Get.Parameters {
+				params = append(params, p.Name)
+			}
+			var props []string
+			for _, kv := range *s.Definitions["Resp"].Properties {
+				props = append(props, kv.Key)
+			}
+			var tags []string
+			for _, tag := range s.Tags {
+				tags = append(tags, tag.Name)
+			}
+			if !reflect.DeepEqual(params, tc.params) {
+				t.Errorf("want parameters %v, got %v", tc.params, params)
+			}
+			if !reflect.DeepEqual(props, tc.props) {
+				t.Errorf("want properties %v, got %v", tc.props, props)
+			}
+			if !reflect.DeepEqual(tags, tc.tags) {
+				t.Errorf("want tags %v, got %v", tc.tags, tags)
+			}
+		})
+	}
+}
+
+func TestSortSwaggerUnsupported(t *testing.T) {
+	if _, err := applyGenerate(newTestPlugin(t, sortAPI, nil), &Config{Sort: "random"}); err == nil {
+		t.Fatal("want error of the unsupported sort")
+	}
+}
+
+func TestGenerateReproducible(t *testing.T) {
+	c := &Config{
+		Parameters: map[string]json.RawMessage{
+			"TraceB": json.RawMessage(`{"name": "X-Trace", "in": "header", "type": "string"}`),
+			"TraceA": json.RawMessage(`{"name": "X-Trace", "in": "header", "type": "string"}`),
+		},
+	}
+	var first []byte
+	for i := 0; i < 20; i++ {
+		s, err := applyGenerate(newTestPlugin(t, sortAPI, nil), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = b
+			// the duplicated shared parameters are referred by the first one in name order.
+			if ref := s.Paths["/items/{id}"].Get.Parameters[2].Ref; ref != "#/parameters/TraceA" {
+				t.Fatalf("want the reference of TraceA, got %q", ref)
+			}
+			continue
+		}
+		if string(b) != string(first) {
+			t.Fatalf("output of run %d differs:\n%s\n%s", i, first, b)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	splitByTag           = "tag"
+	splitByGroup         = "group"
+	splitDefinitionsFile = "file"
+	splitDefinitionsType = "type"
+
+	splitPathsDir        = "paths"
+	splitDefinitionsName = "definitions"
+)
+
+// localRefRegexp matches the local references of the definitions, the shared parameters and the stream definitions.
+var localRefRegexp = regexp.MustCompile(`"\$ref":"#/(definitions|parameters|x-stream-definitions)/([^"]*)"`)
+
+// splitSwagger splits the swagger object into the root document, the paths files and the definitions files,
+// which are wired by the relative external references, it returns the file contents keyed by the relative file names:
+//
+//	rest.swagger.json           the root document, paths and definitions refer to the files below
+//	paths/{tag or group}.json   the path items of the tag or group
+//	definitions.json            the definitions, or definitions/{type}.json for each type
+func splitSwagger(s *swaggerObject, filename string, c *Config) (map[string][]byte, error) {
+	if c.Split != splitByTag && c.Split != splitByGroup {
+		return nil, fmt.Errorf("unsupported split: %s, only support [tag group]", c.Split)
+	}
+	byType := false
+	switch c.SplitDefinitions {
+	case "", splitDefinitionsFile:
+	case splitDefinitionsType:
+		byType = true
+	default:
+		return nil, fmt.Errorf("unsupported split definitions: %s, only support [file type]", c.SplitDefinitions)
+	}
+
+	definitionRef := func(prefix, name string) string {
+		if _, ok := s.Definitions[name]; !ok {
+			// the undefined definitions are still referred by the root document
+			return prefix + filename + "#/" + splitDefinitionsName + "/" + name
+		}
+		if byType {
+			return prefix + splitDefinitionsName + "/" + splitFileName(name) + ".json"
+		}
+		return prefix + splitDefinitionsName + ".json#/" + name
+	}
+	files := make(map[string][]byte)
+	root := *s
+
+	// paths
+	pathFiles := make(map[string]swaggerPathsObject)
+	root.Paths = make(swaggerPathsObject, len(s.Paths))
+	for _, path := range sortedKeys(s.Paths) {
+		item := s.Paths[path]
+		name := splitFileName(pathItemSection(item, c.Split))
+		if pathFiles[name] == nil {
+			pathFiles[name] = make(swaggerPathsObject)
+		}
+		pathFiles[name][path] = item
+		// the json pointer is encoded as the uri fragment since the path contains "{" and "}"
+		root.Paths[path] = swaggerPathItemObject{Ref: splitPathsDir + "/" + name + ".json#/" + url.PathEscape(escapePointer(path))}
+	}
+	for name, paths := range pathFiles {
+		data, err := rewriteRefs(paths, func(section, name string) string {
+			if section == splitDefinitionsName {
+				return definitionRef("../", name)
+			}
+			return "../" + filename + "#/" + section + "/" + name
+		})
+		if err != nil {
+			return nil, err
+		}
+		files[splitPathsDir+"/"+name+".json"] = data
+	}
+
+	// definitions
+	root.Definitions = make(swaggerDefinitionsObject, len(s.Definitions))
+	for name := range s.Definitions {
+		root.Definitions[name] = swaggerSchemaObject{schemaCore: schemaCore{Ref: definitionRef("", name)}}
+	}
+	if byType {
+		for name, schema := range s.Definitions {
+			data, err := rewriteRefs(schema, func(section, name string) string {
+				if section == splitDefinitionsName {
+					if _, ok := s.Definitions[name]; ok {
+						return splitFileName(name) + ".json"
+					}
+				}
+				return "../" + filename + "#/" + section + "/" + name
+			})
+			if err != nil {
+				return nil, err
+			}
+			files[splitDefinitionsName+"/"+splitFileName(name)+".json"] = data
+		}
+	} else {
+		data, err := rewriteRefs(s.Definitions, func(section, name string) string {
+			if _, ok := s.Definitions[name]; ok && section == splitDefinitionsName {
+				return "#/" + name
+			}
+			return filename + "#/" + section + "/" + name
+		})
+		if err != nil {
+			return nil, err
+		}
+		files[splitDefinitionsName+".json"] = data
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	files[filename] = data
+
+	return files, nil
+}
+
+// pathItemSection returns the tag or the group of the first operation of the path item.
+func pathItemSection(item swaggerPathItemObject, split string) string {
+	for _, op := range []*swaggerOperationObject{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+		if op == nil {
+			continue
+		}
+		if split == splitByGroup {
+			return op.group
+		}
+		if len(op.Tags) > 0 {
+			return op.Tags[0]
+		}
+	}
+
+	return "default"
+}
+
+// rewriteRefs marshals the value and rewrites its local references by the function.
+func rewriteRefs(v interface{}, rewrite func(section, name string) string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return localRefRegexp.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := localRefRegexp.FindSubmatch(m)
+		ref, _ := json.Marshal(rewrite(string(sub[1]), string(sub[2])))
+		return append([]byte(`"$ref":`), ref...)
+	}), nil
+}
+
+// splitFileName returns the safe file name of the tag, group or type name.
+func splitFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "default"
+	}
+
+	return name
+}
+
+// escapePointer escapes the json pointer token.
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// unescapePointer unescapes the json pointer token.
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// writeFiles writes the indented json files into the dir.
+func writeFiles(dir string, files map[string][]byte) error {
+	for _, name := range sortedKeys(files) {
+		var formatted bytes.Buffer
+		if err := json.Indent(&formatted, files[name], "", "  "); err != nil {
+			return err
+		}
+		formatted.WriteString("\n")
+
+		output := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, formatted.Bytes(), 0o666); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orderedObject keeps the key order of the json object.
+type orderedObject = swaggerSchemaObjectProperties
+
+// Bundle resolves the external references of the split swagger documents and writes them into one file,
+// the external references of the root document sections like definitions, parameters and paths are inlined,
+// and the references to them are converted to the local references, the others are inlined.
+func Bundle(input, output string) error {
+	input, err := filepath.Abs(input)
+	if err != nil {
+		return err
+	}
+	b := &bundler{root: input, docs: make(map[string]interface{}), locals: make(map[string]string)}
+	doc, err := b.load(input)
+	if err != nil {
+		return err
+	}
+	root, ok := doc.(orderedObject)
+	if !ok {
+		return fmt.Errorf("%s is not a json object", input)
+	}
+
+	// the targets of the root section entries are referred by the local references
+	for _, section := range root {
+		entries, _ := section.Value.(orderedObject)
+		for _, entry := range entries {
+			if ref, ok := refOf(entry.Value); ok && !strings.HasPrefix(ref, "#") {
+				b.locals[b.target(input, ref)] = "#/" + escapePointer(section.Key) + "/" + escapePointer(entry.Key)
+			}
+		}
+	}
+
+	bundled := make(orderedObject, 0, len(root))
+	for _, section := range root {
+		var value interface{}
+		if entries, ok := section.Value.(orderedObject); ok {
+			resolved := make(orderedObject, 0, len(entries))
+			for _, entry := range entries {
+				v, err := b.resolveEntry(entry.Value)
+				if err != nil {
+					return err
+				}
+				resolved = append(resolved, keyVal{Key: entry.Key, Value: v})
+			}
+			value = resolved
+		} else if value, err = b.resolve(section.Value, input); err != nil {
+			return err
+		}
+		bundled = append(bundled, keyVal{Key: section.Key, Value: value})
+	}
+
+	data, err := json.Marshal(bundled)
+	if err != nil {
+		return err
+	}
+	var formatted bytes.Buffer
+	if err := json.Indent(&formatted, data, "", "  "); err != nil {
+		return err
+	}
+	formatted.WriteString("\n")
+
+	return os.WriteFile(output, formatted.Bytes(), 0o666)
+}
+
+type bundler struct {
+	root   string
+	docs   map[string]interface{}
+	locals map[string]string
+}
+
+// resolveEntry inlines the root section entry which refers to the external document.
+func (b *bundler) resolveEntry(v interface{}) (interface{}, error) {
+	ref, ok := refOf(v)
+	if !ok || strings.HasPrefix(ref, "#") {
+		return b.resolve(v, b.root)
+	}
+
+	return b.inline(b.target(b.root, ref))
+}
+
+// resolve resolves the references of the value in the file,
+// the references to the root document and the root section entries are local references, the others are inlined.
+func (b *bundler) resolve(v interface{}, file string) (interface{}, error) {
+	switch val := v.(type) {
+	case orderedObject:
+		if ref, ok := refOf(val); ok {
+			target := b.target(file, ref)
+			if filename, pointer, _ := strings.Cut(target, "#"); filename == b.root {
+				return orderedObject{{Key: "$ref", Value: "#" + pointer}}, nil
+			}
+			if local, ok := b.locals[target]; ok {
+				return orderedObject{{Key: "$ref", Value: local}}, nil
+			}
+			return b.inline(target)
+		}
+
+		ret := make(orderedObject, 0, len(val))
+		for _, kv := range val {
+			resolved, err := b.resolve(kv.Value, file)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, keyVal{Key: kv.Key, Value: resolved})
+		}
+		return ret, nil
+	case []interface{}:
+		ret := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			resolved, err := b.resolve(item, file)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, resolved)
+		}
+		return ret, nil
+	default:
+		return v, nil
+	}
+}
+
+// inline returns the resolved value of the target.
+func (b *bundler) inline(target string) (interface{}, error) {
+	filename, pointer, _ := strings.Cut(target, "#")
+	doc, err := b.load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	v := doc
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = unescapePointer(token)
+			found := false
+			switch val := v.(type) {
+			case orderedObject:
+				for _, kv := range val {
+					if kv.Key == token {
+						v, found = kv.Value, true
+						break
+					}
+				}
+			case []interface{}:
+				if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(val) {
+					v, found = val[i], true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("reference %s is not found", target)
+			}
+		}
+	}
+
+	return b.resolve(v, filename)
+}
+
+// load loads the json document with the key order kept.
+func (b *bundler) load(filename string) (interface{}, error) {
+	if doc, ok := b.docs[filename]; ok {
+		return doc, nil
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	doc, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s err: %w", filename, err)
+	}
+	b.docs[filename] = doc
+
+	return doc, nil
+}
+
+// target returns the canonical target of the reference, which is the absolute file name and the decoded json pointer.
+func (b *bundler) target(base, ref string) string {
+	file, pointer, _ := strings.Cut(ref, "#")
+	if v, err := url.PathUnescape(pointer); err == nil {
+		pointer = v
+	}
+	if file == "" {
+		file = base
+	} else if !filepath.IsAbs(file) {
+		file = filepath.Join(filepath.Dir(base), filepath.FromSlash(file))
+	}
+
+	return file + "#" + pointer
+}
+
+// refOf returns the reference if the value is a reference object.
+func refOf(v interface{}) (string, bool) {
+	obj, ok := v.(orderedObject)
+	if !ok || len(obj) != 1 || obj[0].Key != "$ref" {
+		return "", false
+	}
+	ref, ok := obj[0].Value.(string)
+
+	return ref, ok
+}
+
+// decodeOrdered decodes the json value, the objects are decoded as orderedObject.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		obj := orderedObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, keyVal{Key: key.(string), Value: value})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	default:
+		return t, nil
+	}
+}
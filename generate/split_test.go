@@ -0,0 +1,180 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const splitAPI = `syntax = "v1"
+
+type (
+	Page {
+		Num  int ` + "`json:\"num\"`" + `
+		Size int ` + "`json:\"size\"`" + `
+	}
+	// 订单
+	Order {
+		Id    int64    ` + "`json:\"id\"`" + `
+		Items []Item   ` + "`json:\"items\"`" + `
+		Tags  []string ` + "`json:\"tags,optional\"`" + `
+	}
+	Item {
+		Sku   string ` + "`json:\"sku\"`" + `
+		Count int    ` + "`json:\"count\"`" + `
+	}
+	ListReq {
+		Trace string ` + "`header:\"X-Trace\"`" + `
+		Page  int    ` + "`form:\"page,optional\"`" + `
+	}
+	ListResp {
+		Page   Page    ` + "`json:\"page\"`" + `
+		Orders []Order ` + "`json:\"orders\"`" + `
+	}
+	CreateReq {
+		Trace string ` + "`header:\"X-Trace\"`" + `
+		Items []Item ` + "`json:\"items\"`" + `
+	}
+	Event {
+		Id string ` + "`json:\"id\"`" + `
+	}
+)
+
+@server (
+	group: order
+	prefix: /v1
+)
+service demo {
+	@handler list
+	get /orders (ListReq) returns (ListResp)
+
+	@handler create
+	post /orders (CreateReq) returns (Order)
+
+	@handler get
+	get /orders/:id returns (Order)
+}
+
+@server (
+	group: event
+	sse: true
+)
+service demo {
+	@doc (
+		tags: "stream/events"
+	)
+	@handler events
+	get /events returns (Event)
+}`
+
+func TestSplitBundle(t *testing.T) {
+	cases := []struct {
+		split       string
+		definitions string
+		files       []string
+	}{
+		{
+			split: splitByTag,
+			files: []string{"paths/demo_order.json", "paths/demo_event.json", "definitions.json"},
+		},
+		{
+			split:       splitByGroup,
+			definitions: splitDefinitionsType,
+			files:       []string{"paths/order.json", "paths/event.json", "definitions/Order.json", "definitions/Item.json"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.split+"_"+tc.definitions, func(t *testing.T) {
+			c := &Config{
+				Pack:       "Response",
+				Parameters: map[string]json.RawMessage{"Trace": json.RawMessage(`{"name": "X-Trace", "in": "header", "type": "string"}`)},
+			}
+			p := newTestPlugin(t, splitAPI, nil)
+			if err := Do("rest.swagger.json", c, p); err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join(p.Dir, "rest.swagger.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			split := *c
+			split.Split, split.SplitDefinitions = tc.split, tc.definitions
+			p.Dir = filepath.Join(p.Dir, "split")
+			if err := os.MkdirAll(p.Dir, 0o777); err != nil {
+				t.Fatal(err)
+			}
+			if err := Do("rest.swagger.json", &split, p); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tc.files {
+				if _, err := os.Stat(filepath.Join(p.Dir, name)); err != nil {
+					t.Errorf("want split file %s: %v", name, err)
+				}
+			}
+			root, err := os.ReadFile(filepath.Join(p.Dir, "rest.swagger.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(root, &doc); err != nil {
+				t.Fatal(err)
+			}
+			paths, _ := doc["paths"].(map[string]interface{})
+			for path, item := range paths {
+				if ref, _ := lookup(item, "$ref").(string); !strings.HasPrefix(ref, "paths/") {
+					t.Errorf("want the path %s of the root document to refer to the paths file, got %v", path, item)
+				}
+			}
+			if len(paths) != 3 {
+				t.Errorf("want 3 paths of the root document, got %d", len(paths))
+			}
+
+			output := filepath.Join(t.TempDir(), "bundled.json")
+			if err := Bundle(filepath.Join(p.Dir, "rest.swagger.json"), output); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("bundled document differs from the unsplit one:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSplitUnsupported(t *testing.T) {
+	cases := []struct {
+		name string
+		c    Config
+		want string
+	}{
+		{name: "split", c: Config{Split: "handler"}, want: "unsupported split"},
+		{name: "split definitions", c: Config{Split: splitByTag, SplitDefinitions: "group"}, want: "unsupported split definitions"},
+		{name: "split with per", c: Config{Split: splitByTag, Per: perGroup}, want: "split can not be used with per"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Do("rest.swagger.json", &tc.c, newTestPlugin(t, splitAPI, nil))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("want error with %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestBundleInvalidReference(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "rest.swagger.json")
+	if err := os.WriteFile(input, []byte(`{"swagger": "2.0", "paths": {"/a": {"$ref": "paths/missing.json#/~1a"}}}`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := Bundle(input, filepath.Join(dir, "bundled.json")); err == nil {
+		t.Fatal("want error of the missing file")
+	}
+}
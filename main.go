@@ -45,6 +45,56 @@ var (
 					Usage: "outer packaging response structure, " +
 						"example: " + fmt.Sprintf("%q", generate.DefaultResponseJson),
 				},
+				&cli.StringFlag{
+					Name:  "server", // 指定配置文件中用于生成 host、basepath 及 schemes 的服务器名称
+					Usage: "the server name declared in the config to render host, basepath and schemes",
+				},
+				&cli.StringFlag{
+					Name: "include", // 仅生成匹配的路由，以逗号分隔
+					Usage: "only render the routes which match any of the selectors separated by commas, " +
+						"example: group:order,prefix:/v1,tag:public,handler:get*,internal",
+				},
+				&cli.StringFlag{
+					Name: "exclude", // 排除匹配的路由，以逗号分隔
+					Usage: "exclude the routes which match any of the selectors separated by commas, " +
+						"example: internal,group:admin",
+				},
+				&cli.StringFlag{
+					Name: "split", // 按 tag 或 group 拆分输出文件
+					Usage: "split the output into the root document and the files of the paths by tag or group, " +
+						"example: tag",
+				},
+				&cli.StringFlag{
+					Name: "per", // 按 group、prefix 或路径中的版本分别生成文档
+					Usage: "emit one document per group, prefix or version segment of the path, " +
+						"example: version",
+				},
+				&cli.StringFlag{
+					Name: "sort", // 指定排序方式，declaration 按声明顺序，alphabetical 按字母顺序
+					Usage: "order of the tags, parameters and properties, " +
+						"declaration or alphabetical, default: declaration",
+				},
+				&cli.StringFlag{
+					Name: "config", // 指定配置文件，相对路径基于 api 文件所在目录
+					Usage: "swagger generation config json file, " +
+						"relative path is based on the api file directory",
+				},
+			},
+		},
+		{
+			Name:   "bundle",
+			Usage:  "bundles the split swagger documents into one file",
+			Action: action.Bundler,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "input",
+					Usage:    "the root document of the split swagger documents",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "bundled swagger save file name, default: bundled.swagger.json",
+				},
 			},
 		},
 	}
@@ -57,5 +107,6 @@ func main() {
 	app.Commands = commands
 	if err := app.Run(os.Args); err != nil {
 		fmt.Printf("goctl-swagger: %+v\n", err)
+		os.Exit(1)
 	}
 }